@@ -2,6 +2,8 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
+	"regexp"
 	"strings"
 
 	"github.com/BOBO1997/monkey/token"
@@ -56,9 +58,10 @@ func (p *Program) String() string {
 // LetStatement is a struct for "let" statement
 // "let" is a statement with identifier and expression
 type LetStatement struct {
-	Token token.Token // token.LET
-	Name  *Identifier // note: identifier is a struct
-	Value Expression  // note: Expression is an interface
+	Token token.Token    // token.LET
+	Name  *Identifier    // note: identifier is a struct
+	Type  TypeAnnotation // nil if not annotated
+	Value Expression     // note: Expression is an interface
 }
 
 // StatementNode method of LetStatement struct,
@@ -74,6 +77,9 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -83,6 +89,35 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// UnpackStatement is a struct for "let" statement with several names
+// "let (<name>, <name>, ...) = <expression>" binds the elements of a tuple to the names in order
+type UnpackStatement struct {
+	Token token.Token // token.LET
+	Names []*Identifier
+	Value Expression
+}
+
+// StatementNode method of UnpackStatement struct,
+func (us *UnpackStatement) StatementNode() {}
+
+// TokenLiteral method of UnpackStatement struct
+func (us *UnpackStatement) TokenLiteral() string {
+	return us.Token.Literal
+}
+
+// String method of UnpackStatement struct
+func (us *UnpackStatement) String() string {
+	names := []string{}
+	for _, name := range us.Names {
+		names = append(names, name.String())
+	}
+	value := ""
+	if us.Value != nil {
+		value = us.Value.String()
+	}
+	return us.TokenLiteral() + " (" + strings.Join(names, ", ") + ") = " + value + ";"
+}
+
 // ReturnStatement is a struct
 type ReturnStatement struct {
 	Token       token.Token // token.RETURN
@@ -108,6 +143,194 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// ThrowStatement is a struct
+type ThrowStatement struct {
+	Token token.Token // token.THROW
+	Value Expression  // note: Expression is an interface
+}
+
+// StatementNode method of ThrowStatement struct,
+func (ts *ThrowStatement) StatementNode() {}
+
+// TokenLiteral method of ThrowStatement struct
+func (ts *ThrowStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+// String method of ThrowStatement struct
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ts.TokenLiteral() + " ")
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
+// DeferStatement is a struct
+// the call is postponed until the enclosing function call returns
+type DeferStatement struct {
+	Token token.Token     // token.DEFER
+	Call  *CallExpression // note: only a function call can be deferred
+}
+
+// StatementNode method of DeferStatement struct,
+func (ds *DeferStatement) StatementNode() {}
+
+// TokenLiteral method of DeferStatement struct
+func (ds *DeferStatement) TokenLiteral() string {
+	return ds.Token.Literal
+}
+
+// String method of DeferStatement struct
+func (ds *DeferStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ds.TokenLiteral() + " ")
+	if ds.Call != nil {
+		out.WriteString(ds.Call.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
+// FunctionStatement is a struct for a named function declaration
+// the declaration is hoisted to the beginning of the enclosing block
+type FunctionStatement struct {
+	Token    token.Token // token.FUNCTION
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+// StatementNode method of FunctionStatement struct,
+func (fs *FunctionStatement) StatementNode() {}
+
+// TokenLiteral method of FunctionStatement struct
+func (fs *FunctionStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+// String method of FunctionStatement struct
+func (fs *FunctionStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(fs.TokenLiteral() + " ")
+	out.WriteString(fs.Name.String())
+	out.WriteString(fs.Function.signature())
+	out.WriteString(fs.Function.Body.String())
+	return out.String()
+}
+
+// StructStatement is a struct for a struct declaration
+// "struct <name> { <field>, <field>, ... }" defines a record type with the fixed fields
+type StructStatement struct {
+	Token  token.Token // token.STRUCT
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+// StatementNode method of StructStatement struct,
+func (ss *StructStatement) StatementNode() {}
+
+// TokenLiteral method of StructStatement struct
+func (ss *StructStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+// String method of StructStatement struct
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+	out.WriteString(ss.TokenLiteral() + " ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
+
+// EnumStatement is a struct for an enum declaration
+// "enum <name> { <variant>, <variant>(<field>, ...), ... }" defines tagged variants, optionally carrying payloads
+type EnumStatement struct {
+	Token    token.Token // token.ENUM
+	Name     *Identifier
+	Variants []*EnumVariant
+}
+
+// EnumVariant struct is a variant of an enum declaration
+type EnumVariant struct {
+	Name   *Identifier
+	Fields []*Identifier // names of the payloads, empty if the variant carries nothing
+}
+
+// String method of EnumVariant struct
+func (ev *EnumVariant) String() string {
+	if len(ev.Fields) == 0 {
+		return ev.Name.String()
+	}
+	fields := []string{}
+	for _, f := range ev.Fields {
+		fields = append(fields, f.String())
+	}
+	return ev.Name.String() + "(" + strings.Join(fields, ", ") + ")"
+}
+
+// StatementNode method of EnumStatement struct,
+func (es *EnumStatement) StatementNode() {}
+
+// TokenLiteral method of EnumStatement struct
+func (es *EnumStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+
+// String method of EnumStatement struct
+func (es *EnumStatement) String() string {
+	var out bytes.Buffer
+	variants := []string{}
+	for _, v := range es.Variants {
+		variants = append(variants, v.String())
+	}
+	out.WriteString(es.TokenLiteral() + " ")
+	out.WriteString(es.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(variants, ", "))
+	out.WriteString(" }")
+	return out.String()
+}
+
+// YieldStatement is a struct
+// yield suspends the enclosing generator function and produces the value
+// "yield ...<iterable>" delegates to the iterable, producing each of its elements
+type YieldStatement struct {
+	Token    token.Token // token.YIELD
+	Value    Expression  // note: Expression is an interface
+	Delegate bool
+}
+
+// StatementNode method of YieldStatement struct,
+func (ys *YieldStatement) StatementNode() {}
+
+// TokenLiteral method of YieldStatement struct
+func (ys *YieldStatement) TokenLiteral() string {
+	return ys.Token.Literal
+}
+
+// String method of YieldStatement struct
+func (ys *YieldStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ys.TokenLiteral() + " ")
+	if ys.Delegate {
+		out.WriteString("...")
+	}
+	if ys.Value != nil {
+		out.WriteString(ys.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
 // ExpressionStatement is a struct
 type ExpressionStatement struct {
 	Token      token.Token
@@ -168,6 +391,45 @@ func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+// BigIntegerLiteral is a struct for an integer literal which does not fit in int64
+type BigIntegerLiteral struct {
+	Token token.Token // token.INT
+	Value *big.Int
+}
+
+// expressionNode method of BigIntegerLiteral struct
+func (bl *BigIntegerLiteral) expressionNode() {}
+
+// TokenLiteral method of BigIntegerLiteral struct
+func (bl *BigIntegerLiteral) TokenLiteral() string {
+	return bl.Token.Literal
+}
+
+// String method of BigIntegerLiteral struct
+func (bl *BigIntegerLiteral) String() string {
+	return bl.Token.Literal
+}
+
+// DecimalLiteral is a struct for token.DECIMAL
+type DecimalLiteral struct {
+	Token token.Token // token.DECIMAL, such as 12.50d
+	Value *big.Int    // unscaled value, such as 1250
+	Scale int         // number of the digits after the decimal point, such as 2
+}
+
+// expressionNode method of DecimalLiteral struct
+func (dl *DecimalLiteral) expressionNode() {}
+
+// TokenLiteral method of DecimalLiteral struct
+func (dl *DecimalLiteral) TokenLiteral() string {
+	return dl.Token.Literal
+}
+
+// String method of DecimalLiteral struct
+func (dl *DecimalLiteral) String() string {
+	return dl.Token.Literal
+}
+
 // StringLiteral is a struct for token.String
 type StringLiteral struct {
 	Token token.Token
@@ -187,6 +449,45 @@ func (sl *StringLiteral) String() string {
 	return sl.Token.Literal
 }
 
+// BytesLiteral is a struct for token.BYTES
+type BytesLiteral struct {
+	Token token.Token // token.BYTES, such as b"\x00"
+	Value []byte
+}
+
+// expressionNode method of BytesLiteral struct
+func (bl *BytesLiteral) expressionNode() {}
+
+// TokenLiteral method of BytesLiteral struct
+func (bl *BytesLiteral) TokenLiteral() string {
+	return bl.Token.Literal
+}
+
+// String method of BytesLiteral struct
+func (bl *BytesLiteral) String() string {
+	return bl.Token.Literal
+}
+
+// RegexLiteral is a struct for token.REGEX
+// the pattern is compiled once by the parser
+type RegexLiteral struct {
+	Token token.Token // token.REGEX, such as r"\d+"
+	Value *regexp.Regexp
+}
+
+// expressionNode method of RegexLiteral struct
+func (rl *RegexLiteral) expressionNode() {}
+
+// TokenLiteral method of RegexLiteral struct
+func (rl *RegexLiteral) TokenLiteral() string {
+	return rl.Token.Literal
+}
+
+// String method of RegexLiteral struct
+func (rl *RegexLiteral) String() string {
+	return rl.Token.Literal
+}
+
 // ArrayLiteral is a struct for token.Array
 type ArrayLiteral struct {
 	Token    token.Token
@@ -214,15 +515,69 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+// TupleLiteral is a struct
+// "(<element>, <element>, ...)" is a tuple, where a single element needs a trailing comma as "(<element>,)"
+type TupleLiteral struct {
+	Token    token.Token // token.LPAREN
+	Elements []Expression
+}
+
+// expressionNode method of TupleLiteral struct
+func (tl *TupleLiteral) expressionNode() {}
+
+// TokenLiteral method of TupleLiteral struct
+func (tl *TupleLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+
+// String method of TupleLiteral struct
+func (tl *TupleLiteral) String() string {
+	elements := []string{}
+	for _, element := range tl.Elements {
+		elements = append(elements, element.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// SetLiteral is a struct for token.HASHBRACE
+// "#{<element>, ...}" is a set of the elements, which can be spread
+type SetLiteral struct {
+	Token    token.Token // token.HASHBRACE
+	Elements []Expression
+}
+
+// expressionNode method of SetLiteral struct
+func (sl *SetLiteral) expressionNode() {}
+
+// TokenLiteral method of SetLiteral struct
+func (sl *SetLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+// String method of SetLiteral struct
+func (sl *SetLiteral) String() string {
+	elements := []string{}
+	for _, element := range sl.Elements {
+		elements = append(elements, element.String())
+	}
+	return "#{" + strings.Join(elements, ", ") + "}"
+}
+
 // expressions
 
 // index expression
 
 // IndexExpression struct
+// "<left>.<name>" is an index expression with the name as a string literal
+// Optional is set for "<left>?[<index>]" and "<left>?.<name>", which evaluate to null when left is null
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token // token.LBRACKET, token.DOT, token.QLBRACKET or token.QDOT
+	Left     Expression
+	Index    Expression
+	Optional bool
 }
 
 // expressionNode method of IndexExpression struct
@@ -237,16 +592,64 @@ func (ie *IndexExpression) TokenLiteral() string {
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(ie.Left.String())
+	if ie.Token.Type == token.DOT || ie.Token.Type == token.QDOT {
+		out.WriteString(ie.Token.Literal)
+		out.WriteString(ie.Index.String())
+		return out.String()
+	}
+	if ie.Optional {
+		out.WriteString("?")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("]")
 	return out.String()
 }
 
+// UpdateExpression struct
+// "<record> with { <field>: <value>, ... }" copies the record, replacing the given fields
+type UpdateExpression struct {
+	Token  token.Token // token.WITH
+	Record Expression
+	Fields []*Identifier
+	Values []Expression // Values[i] is the new value of Fields[i]
+}
+
+// expressionNode method of UpdateExpression struct
+func (ue *UpdateExpression) expressionNode() {}
+
+// TokenLiteral method of UpdateExpression struct
+func (ue *UpdateExpression) TokenLiteral() string {
+	return ue.Token.Literal
+}
+
+// String method of UpdateExpression struct
+func (ue *UpdateExpression) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, f := range ue.Fields {
+		fields = append(fields, f.String()+": "+ue.Values[i].String())
+	}
+	out.WriteString("(")
+	out.WriteString(ue.Record.String())
+	out.WriteString(" with {")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("})")
+	return out.String()
+}
+
 // HashLiteral is a struct for token.Hash
+// Pairs are kept in source order, in which they are evaluated and merged, so that a later key takes precedence
 type HashLiteral struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Pairs []HashLiteralPair
+}
+
+// HashLiteralPair is a struct for a key and its value in a hash literal, or a spread of a hash merged in its place
+type HashLiteralPair struct {
+	Key    Expression
+	Value  Expression
+	Spread *SpreadExpression // set only for a spread, without Key and Value
 }
 
 // expressionNode method of HashLiteral struct
@@ -261,8 +664,12 @@ func (hl *HashLiteral) TokenLiteral() string {
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, item := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+item.String())
+	for _, pair := range hl.Pairs {
+		if pair.Spread != nil {
+			pairs = append(pairs, pair.Spread.String())
+			continue
+		}
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -270,6 +677,26 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+// SpreadExpression is a struct
+// "...<value>" splices an array into a call or an array literal, and merges a hash into a hash literal
+type SpreadExpression struct {
+	Token token.Token // token.ELLIPSIS
+	Value Expression
+}
+
+// expressionNode method of SpreadExpression struct
+func (se *SpreadExpression) expressionNode() {}
+
+// TokenLiteral method of SpreadExpression struct
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+// String method of SpreadExpression struct
+func (se *SpreadExpression) String() string {
+	return se.TokenLiteral() + se.Value.String()
+}
+
 // prefix expression
 
 // PrefixExpression is a struct
@@ -343,6 +770,24 @@ func (b *Boolean) String() string {
 	return b.Token.Literal
 }
 
+// NullLiteral is a struct
+type NullLiteral struct {
+	Token token.Token // token.NULL
+}
+
+// expressionNode method of NullLiteral struct
+func (nl *NullLiteral) expressionNode() {}
+
+// TokenLiteral method of NullLiteral struct
+func (nl *NullLiteral) TokenLiteral() string {
+	return nl.Token.Literal
+}
+
+// String method of NullLiteral struct
+func (nl *NullLiteral) String() string {
+	return nl.Token.Literal
+}
+
 // IfExpression is a struct
 type IfExpression struct {
 	Token       token.Token
@@ -374,6 +819,42 @@ func (ife *IfExpression) String() string {
 	return out.String()
 }
 
+// TryExpression is a struct
+// Catch and Finally are optional, but at least one of them is set by the parser
+type TryExpression struct {
+	Token      token.Token // token.TRY
+	Block      *BlockStatement
+	CatchParam *Identifier // bound to the caught value inside Catch
+	Catch      *BlockStatement
+	Finally    *BlockStatement
+}
+
+// expressionNode method of TryExpression struct
+func (te *TryExpression) expressionNode() {}
+
+// TokenLiteral method of TryExpression struct
+func (te *TryExpression) TokenLiteral() string {
+	return te.Token.Literal
+}
+
+// String method of TryExpression struct
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+	if te.Catch != nil {
+		out.WriteString(" catch(")
+		out.WriteString(te.CatchParam.String())
+		out.WriteString(") ")
+		out.WriteString(te.Catch.String())
+	}
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+	return out.String()
+}
+
 // BlockStatement is a struct
 type BlockStatement struct {
 	Token      token.Token
@@ -401,9 +882,13 @@ func (bs *BlockStatement) String() string {
 
 // FunctionLiteral is a struct
 type FunctionLiteral struct {
-	Token      token.Token
-	Parameters []*Identifier
-	Body       *BlockStatement
+	Token          token.Token
+	Name           string // name given by a function declaration or a let statement, empty if anonymous
+	Parameters     []*Identifier
+	ParameterTypes []TypeAnnotation // ParameterTypes[i] is the annotation of Parameters[i], nil if not annotated
+	ReturnType     TypeAnnotation   // nil if not annotated
+	Body           *BlockStatement
+	IsGenerator    bool // set when the body contains yield, outside of nested functions
 }
 
 // expressionNode method of FunctionLiteral struct
@@ -416,16 +901,30 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 
 // String method of FunctionLiteral struct
 func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+	out.WriteString(fl.TokenLiteral())
+	out.WriteString(fl.signature())
+	out.WriteString(fl.Body.String())
+	return out.String()
+}
+
+// signature method of FunctionLiteral struct returns the parameters and the return type with their annotations
+func (fl *FunctionLiteral) signature() string {
 	var out bytes.Buffer
 	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			params = append(params, p.String()+": "+fl.ParameterTypes[i].String())
+		} else {
+			params = append(params, p.String())
+		}
 	}
-	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
-	out.WriteString(fl.Body.String())
+	if fl.ReturnType != nil {
+		out.WriteString(" -> " + fl.ReturnType.String())
+	}
 	return out.String()
 }
 
@@ -487,3 +986,142 @@ func (ml *MacroLiteral) String() string {
 	out.WriteString(ml.Body.String())
 	return out.String()
 }
+
+// type annotations
+
+// TypeAnnotation interface is implemented by the nodes of type annotations
+/*
+	Annotations are optional and checked only by the static type checker (package types).
+	The evaluator ignores them.
+*/
+type TypeAnnotation interface {
+	Node
+	typeNode()
+}
+
+// NamedType struct is a type annotation by name, such as int, string or the name of a struct
+type NamedType struct {
+	Token token.Token // token.IDENT or token.NULL
+	Name  string
+}
+
+// typeNode method of NamedType struct
+func (nt *NamedType) typeNode() {}
+
+// TokenLiteral method of NamedType struct
+func (nt *NamedType) TokenLiteral() string {
+	return nt.Token.Literal
+}
+
+// String method of NamedType struct
+func (nt *NamedType) String() string {
+	return nt.Name
+}
+
+// ArrayType struct is a type annotation "[<element>]"
+type ArrayType struct {
+	Token   token.Token // token.LBRACKET
+	Element TypeAnnotation
+}
+
+// typeNode method of ArrayType struct
+func (at *ArrayType) typeNode() {}
+
+// TokenLiteral method of ArrayType struct
+func (at *ArrayType) TokenLiteral() string {
+	return at.Token.Literal
+}
+
+// String method of ArrayType struct
+func (at *ArrayType) String() string {
+	return "[" + at.Element.String() + "]"
+}
+
+// TupleType struct is a type annotation "(<element>, <element>, ...)"
+type TupleType struct {
+	Token    token.Token // token.LPAREN
+	Elements []TypeAnnotation
+}
+
+// typeNode method of TupleType struct
+func (tt *TupleType) typeNode() {}
+
+// TokenLiteral method of TupleType struct
+func (tt *TupleType) TokenLiteral() string {
+	return tt.Token.Literal
+}
+
+// String method of TupleType struct
+func (tt *TupleType) String() string {
+	elements := []string{}
+	for _, element := range tt.Elements {
+		elements = append(elements, element.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// SetType struct is a type annotation "#{<element>}"
+type SetType struct {
+	Token   token.Token // token.HASHBRACE
+	Element TypeAnnotation
+}
+
+// typeNode method of SetType struct
+func (st *SetType) typeNode() {}
+
+// TokenLiteral method of SetType struct
+func (st *SetType) TokenLiteral() string {
+	return st.Token.Literal
+}
+
+// String method of SetType struct
+func (st *SetType) String() string {
+	return "#{" + st.Element.String() + "}"
+}
+
+// HashType struct is a type annotation "{<key>: <value>}"
+type HashType struct {
+	Token token.Token // token.LBRACE
+	Key   TypeAnnotation
+	Value TypeAnnotation
+}
+
+// typeNode method of HashType struct
+func (ht *HashType) typeNode() {}
+
+// TokenLiteral method of HashType struct
+func (ht *HashType) TokenLiteral() string {
+	return ht.Token.Literal
+}
+
+// String method of HashType struct
+func (ht *HashType) String() string {
+	return "{" + ht.Key.String() + ": " + ht.Value.String() + "}"
+}
+
+// FunctionType struct is a type annotation "fn(<parameter>, ...) -> <return>"
+type FunctionType struct {
+	Token      token.Token // token.FUNCTION
+	Parameters []TypeAnnotation
+	Return     TypeAnnotation
+}
+
+// typeNode method of FunctionType struct
+func (ft *FunctionType) typeNode() {}
+
+// TokenLiteral method of FunctionType struct
+func (ft *FunctionType) TokenLiteral() string {
+	return ft.Token.Literal
+}
+
+// String method of FunctionType struct
+func (ft *FunctionType) String() string {
+	params := []string{}
+	for _, p := range ft.Parameters {
+		params = append(params, p.String())
+	}
+	return "fn(" + strings.Join(params, ", ") + ") -> " + ft.Return.String()
+}
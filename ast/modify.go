@@ -32,8 +32,26 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		}
 	case *ReturnStatement:
 		node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
+	case *ThrowStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *FunctionStatement:
+		node.Function, _ = Modify(node.Function, modifier).(*FunctionLiteral)
+	case *YieldStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *DeferStatement:
+		node.Call, _ = Modify(node.Call, modifier).(*CallExpression)
+	case *TryExpression:
+		node.Block, _ = Modify(node.Block, modifier).(*BlockStatement)
+		if node.Catch != nil {
+			node.Catch, _ = Modify(node.Catch, modifier).(*BlockStatement)
+		}
+		if node.Finally != nil {
+			node.Finally, _ = Modify(node.Finally, modifier).(*BlockStatement)
+		}
 	case *LetStatement:
 		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *UnpackStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *FunctionLiteral:
 		for i := range node.Parameters {
 			node.Parameters[i], _ = Modify(node.Parameters[i], modifier).(*Identifier)
@@ -43,14 +61,35 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		for i := range node.Elements {
 			node.Elements[i], _ = Modify(node.Elements[i], modifier).(Expression)
 		}
+	case *SetLiteral:
+		for i := range node.Elements {
+			node.Elements[i], _ = Modify(node.Elements[i], modifier).(Expression)
+		}
+	case *TupleLiteral:
+		for i := range node.Elements {
+			node.Elements[i], _ = Modify(node.Elements[i], modifier).(Expression)
+		}
+	case *CallExpression:
+		node.Function, _ = Modify(node.Function, modifier).(Expression)
+		for i := range node.Arguments {
+			node.Arguments[i], _ = Modify(node.Arguments[i], modifier).(Expression)
+		}
+	case *UpdateExpression:
+		node.Record, _ = Modify(node.Record, modifier).(Expression)
+		for i := range node.Values {
+			node.Values[i], _ = Modify(node.Values[i], modifier).(Expression)
+		}
+	case *SpreadExpression:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *HashLiteral:
-		newPairs := make(map[Expression]Expression)
-		for key, item := range node.Pairs {
-			newKey, _ := Modify(key, modifier).(Expression)
-			newItem, _ := Modify(item, modifier).(Expression)
-			newPairs[newKey] = newItem
+		for i := range node.Pairs {
+			if node.Pairs[i].Spread != nil {
+				node.Pairs[i].Spread, _ = Modify(node.Pairs[i].Spread, modifier).(*SpreadExpression)
+				continue
+			}
+			node.Pairs[i].Key, _ = Modify(node.Pairs[i].Key, modifier).(Expression)
+			node.Pairs[i].Value, _ = Modify(node.Pairs[i].Value, modifier).(Expression)
 		}
-		node.Pairs = newPairs
 	}
 	return modifier(node)
 }
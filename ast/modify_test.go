@@ -45,6 +45,44 @@ func TestModify(t *testing.T) {
 		},
 	}
 
+	tests = append(tests, []struct {
+		input    Node
+		expected Node
+	}{
+		{
+			&ArrayLiteral{Elements: []Expression{one(), &SpreadExpression{Value: one()}}},
+			&ArrayLiteral{Elements: []Expression{two(), &SpreadExpression{Value: two()}}},
+		},
+		{
+			&CallExpression{Function: one(), Arguments: []Expression{&SpreadExpression{Value: one()}}},
+			&CallExpression{Function: two(), Arguments: []Expression{&SpreadExpression{Value: two()}}},
+		},
+		{
+			&UpdateExpression{Record: one(), Fields: []*Identifier{{Value: "x"}}, Values: []Expression{one()}},
+			&UpdateExpression{Record: two(), Fields: []*Identifier{{Value: "x"}}, Values: []Expression{two()}},
+		},
+		{
+			&HashLiteral{Pairs: []HashLiteralPair{{Spread: &SpreadExpression{Value: one()}}, {Key: one(), Value: one()}}},
+			&HashLiteral{Pairs: []HashLiteralPair{{Spread: &SpreadExpression{Value: two()}}, {Key: two(), Value: two()}}},
+		},
+		{
+			&HashLiteral{Pairs: []HashLiteralPair{{Key: one(), Value: one()}, {Key: two(), Value: one()}}},
+			&HashLiteral{Pairs: []HashLiteralPair{{Key: two(), Value: two()}, {Key: two(), Value: two()}}},
+		},
+		{
+			&SetLiteral{Elements: []Expression{one(), one()}},
+			&SetLiteral{Elements: []Expression{two(), two()}},
+		},
+		{
+			&TupleLiteral{Elements: []Expression{one(), one()}},
+			&TupleLiteral{Elements: []Expression{two(), two()}},
+		},
+		{
+			&UnpackStatement{Names: []*Identifier{{Value: "a"}}, Value: one()},
+			&UnpackStatement{Names: []*Identifier{{Value: "a"}}, Value: two()},
+		},
+	}...)
+
 	for _, tt := range tests {
 		modified := Modify(tt.input, turnOneIntoTwo)
 
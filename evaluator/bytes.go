@@ -0,0 +1,310 @@
+package evaluator
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/object"
+)
+
+// maxBytesSize is the largest size of the zeroed buffer which bytes(n) allocates
+const maxBytesSize = 1 << 30
+
+// bytesBuiltins defines the builtin functions for bytes
+var bytesBuiltins = map[string]*object.Builtin{
+	"bytes": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Bytes:
+				return arg
+			case *object.Integer: // a zeroed buffer of the size
+				if arg.Value < 0 {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "size must not be negative, got %d", arg.Value)
+				}
+				if arg.Value > maxBytesSize {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "size must be at most %d, got %d", maxBytesSize, arg.Value)
+				}
+				return &object.Bytes{Value: make([]byte, arg.Value)}
+			case *object.String:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `bytes` not supported, got STRING, use `encode`")
+			case object.Iterable:
+				elements, err := collectElements(arg.Iterator())
+				if err != nil {
+					return err
+				}
+				value := make([]byte, len(elements))
+				for i, element := range elements {
+					integer, ok := element.(*object.Integer)
+					if !ok {
+						return newErrorOfKind(object.TYPE_ERROR, "byte must be INTEGER, got %s", element.Type())
+					}
+					if integer.Value < 0 || 255 < integer.Value {
+						return newError("byte out of range: %d", integer.Value)
+					}
+					value[i] = byte(integer.Value)
+				}
+				return &object.Bytes{Value: value}
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `bytes` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"encode": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `encode` not supported, got %s", args[0].Type())
+			}
+			encoding, err := getEncoding(args[1], "encode")
+			if err != nil {
+				return err
+			}
+			return encoding.encode(str.Value)
+		},
+	},
+	"decode": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			b, ok := args[0].(*object.Bytes)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `decode` not supported, got %s", args[0].Type())
+			}
+			encoding, err := getEncoding(args[1], "decode")
+			if err != nil {
+				return err
+			}
+			return encoding.decode(b.Value)
+		},
+	},
+	"hex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			b, ok := args[0].(*object.Bytes)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `hex` not supported, got %s", args[0].Type())
+			}
+			return &object.String{Value: hex.EncodeToString(b.Value)}
+		},
+	},
+	"unhex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `unhex` not supported, got %s", args[0].Type())
+			}
+			value, err := hex.DecodeString(str.Value)
+			if err != nil {
+				return newError("invalid hex: %s", err)
+			}
+			return &object.Bytes{Value: value}
+		},
+	},
+	"base64": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			b, ok := args[0].(*object.Bytes)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `base64` not supported, got %s", args[0].Type())
+			}
+			return &object.String{Value: base64.StdEncoding.EncodeToString(b.Value)}
+		},
+	},
+	"unbase64": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `unbase64` not supported, got %s", args[0].Type())
+			}
+			value, err := base64.StdEncoding.DecodeString(str.Value)
+			if err != nil {
+				return newError("invalid base64: %s", err)
+			}
+			return &object.Bytes{Value: value}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range bytesBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// encoding struct converts strings to bytes and back
+type encoding struct {
+	encode func(string) object.Object
+	decode func([]byte) object.Object
+}
+
+// encodings are the encodings known to encode and decode, by name
+var encodings = map[string]encoding{
+	"utf-8": {
+		encode: func(s string) object.Object {
+			return &object.Bytes{Value: []byte(s)}
+		},
+		decode: func(b []byte) object.Object {
+			for i := 0; i < len(b); {
+				r, size := utf8.DecodeRune(b[i:])
+				if r == utf8.RuneError && size <= 1 {
+					return newError("invalid utf-8 at byte %d", i)
+				}
+				i += size
+			}
+			return &object.String{Value: string(b)}
+		},
+	},
+	"ascii": {
+		encode: func(s string) object.Object { return encodeRunes(s, "ascii", 0x7f) },
+		decode: func(b []byte) object.Object {
+			for i, c := range b {
+				if c > 0x7f {
+					return newError("invalid ascii at byte %d", i)
+				}
+			}
+			return &object.String{Value: string(b)}
+		},
+	},
+	"latin-1": {
+		encode: func(s string) object.Object { return encodeRunes(s, "latin-1", 0xff) },
+		decode: func(b []byte) object.Object {
+			runes := make([]rune, len(b))
+			for i, c := range b {
+				runes[i] = rune(c)
+			}
+			return &object.String{Value: string(runes)}
+		},
+	},
+	"utf-16le": {
+		encode: func(s string) object.Object { return encodeUTF16(s, false) },
+		decode: func(b []byte) object.Object { return decodeUTF16(b, false) },
+	},
+	"utf-16be": {
+		encode: func(s string) object.Object { return encodeUTF16(s, true) },
+		decode: func(b []byte) object.Object { return decodeUTF16(b, true) },
+	},
+}
+
+// getEncoding function returns the encoding named by obj
+func getEncoding(obj object.Object, name string) (encoding, *object.Error) {
+	str, ok := obj.(*object.String)
+	if !ok {
+		return encoding{}, newErrorOfKind(object.TYPE_ERROR, "encoding of `%s` must be STRING, got %s", name, obj.Type())
+	}
+	enc, ok := encodings[str.Value]
+	if !ok {
+		return encoding{}, newErrorOfKind(object.ARGUMENT_ERROR, "unknown encoding: %s", str.Value)
+	}
+	return enc, nil
+}
+
+// encodeRunes function encodes each rune of s in a byte, failing on the runes above max
+func encodeRunes(s string, name string, max rune) object.Object {
+	value := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > max {
+			return newError("cannot encode %q in %s", r, name)
+		}
+		value = append(value, byte(r))
+	}
+	return &object.Bytes{Value: value}
+}
+
+// encodeUTF16 function encodes s in UTF-16 of the byte order
+func encodeUTF16(s string, bigEndian bool) object.Object {
+	units := utf16.Encode([]rune(s))
+	value := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		if bigEndian {
+			value = append(value, byte(unit>>8), byte(unit))
+		} else {
+			value = append(value, byte(unit), byte(unit>>8))
+		}
+	}
+	return &object.Bytes{Value: value}
+}
+
+// decodeUTF16 function decodes b in UTF-16 of the byte order
+func decodeUTF16(b []byte, bigEndian bool) object.Object {
+	if len(b)%2 != 0 {
+		return newError("odd number of bytes for utf-16: %d", len(b))
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		} else {
+			units[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
+		}
+	}
+	runes := make([]rune, 0, len(units))
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) { // a high surrogate must be followed by a low one
+			if i+1 == len(units) {
+				return newError("invalid utf-16 at byte %d", 2*i)
+			}
+			if r = utf16.DecodeRune(r, rune(units[i+1])); r == utf8.RuneError {
+				return newError("invalid utf-16 at byte %d", 2*i)
+			}
+			i++
+		}
+		runes = append(runes, r)
+	}
+	return &object.String{Value: string(runes)}
+}
+
+// evalBytesLiteral function
+func evalBytesLiteral(literal *ast.BytesLiteral) object.Object {
+	return &object.Bytes{Value: literal.Value}
+}
+
+// evalBytesInfixExpression function
+// this function is only called when left and right are both *object.Bytes type
+func evalBytesInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Bytes).Value
+	rightVal := right.(*object.Bytes).Value
+	switch operator {
+	case "+":
+		value := make([]byte, 0, len(leftVal)+len(rightVal))
+		return &object.Bytes{Value: append(append(value, leftVal...), rightVal...)}
+	case "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	case "<", ">", "<=", ">=":
+		return evalComparison(operator, left, right)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalBytesIndexExpression function accesses the index-th byte as an integer
+func evalBytesIndexExpression(b, index object.Object) object.Object {
+	value := b.(*object.Bytes).Value
+	idx := index.(*object.Integer).Value
+	if idx < 0 || int64(len(value)) <= idx {
+		return NULL
+	}
+	return &object.Integer{Value: int64(value[idx])}
+}
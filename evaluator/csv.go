@@ -0,0 +1,393 @@
+package evaluator
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BOBO1997/monkey/object"
+)
+
+// csvBuiltins defines the builtin functions for CSV
+/*
+	The options are a hash of
+	"delimiter", the character between the fields ("," by default),
+	"quote", the character quoting the fields ("\"" by default, and "" for no quoting),
+	"header", whether the first row names the fields, so that the other rows are hashes keyed by the names, and
+	"quoting", which fields csv_format quotes: "minimal" (the fields which need it, by default), "all" or "none".
+*/
+var csvBuiltins = map[string]*object.Builtin{
+	// csv_parse(text, options) reads all the rows of the text
+	"csv_parse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1..2", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `csv_parse` not supported, got %s", args[0].Type())
+			}
+			options, err := getCSVOptions(args[1:], "csv_parse")
+			if err != nil {
+				return err
+			}
+			rows, errObj := collectElements(newCSVIterator(strings.NewReader(str.Value), nil, options))
+			if errObj != nil {
+				return errObj
+			}
+			return object.NewArray(rows)
+		},
+	},
+	// csv_open(path, options) reads the rows of the file one by one, as the iterator is advanced
+	// the file is closed when the iterator is exhausted, when a builtin stops reading it early, or by close, as in defer close(rows)
+	"csv_open": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1..2", len(args))
+			}
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `csv_open` not supported, got %s", args[0].Type())
+			}
+			options, err := getCSVOptions(args[1:], "csv_open")
+			if err != nil {
+				return err
+			}
+			file, openErr := os.Open(path.Value)
+			if openErr != nil {
+				return newError("%s", openErr)
+			}
+			return &object.Iter{Source: newCSVIterator(bufio.NewReader(file), file, options)}
+		},
+	},
+	// csv_format(rows, options) writes the rows, which are arrays (or tuples) of the fields or hashes
+	// the header of the hashes is written from the keys of the first one, unless "header" is false
+	"csv_format": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1..2", len(args))
+			}
+			iterator, err := getIterator(args[0], "csv_format")
+			if err != nil {
+				return err
+			}
+			options, err := getCSVOptions(args[1:], "csv_format")
+			if err != nil {
+				return err
+			}
+			return formatCSV(iterator, options)
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range csvBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// csvOptions struct holds the options of the CSV builtins
+type csvOptions struct {
+	delimiter rune
+	quote     rune // 0 for no quoting
+	header    bool
+	headerSet bool // whether header is given, as csv_format writes the header of hashes by default
+	quoting   string
+}
+
+// getCSVOptions function reads the options given as the rest of the arguments of the builtin
+func getCSVOptions(args []object.Object, name string) (csvOptions, *object.Error) {
+	options := csvOptions{delimiter: ',', quote: '"', quoting: "minimal"}
+	if len(args) == 0 {
+		return options, nil
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return options, newErrorOfKind(object.TYPE_ERROR, "options of `%s` must be HASH, got %s", name, args[0].Type())
+	}
+	for _, pair := range hash.Pairs() {
+		key, ok := pair.Key.(*object.String)
+		if !ok {
+			return options, newErrorOfKind(object.ARGUMENT_ERROR, "unknown option of `%s`: %s", name, pair.Key.Inspect())
+		}
+		switch key.Value {
+		case "delimiter", "quote":
+			value, ok := pair.Value.(*object.String)
+			if !ok {
+				return options, newErrorOfKind(object.TYPE_ERROR, "%s of `%s` must be STRING, got %s", key.Value, name, pair.Value.Type())
+			}
+			ch, size := utf8.DecodeRuneInString(value.Value)
+			switch {
+			case key.Value == "quote" && value.Value == "":
+				options.quote = 0
+			case value.Value == "" || ch == utf8.RuneError || size != len(value.Value) || ch == '\n' || ch == '\r':
+				return options, newErrorOfKind(object.ARGUMENT_ERROR, "%s of `%s` must be a character other than newlines, got %q", key.Value, name, value.Value)
+			case key.Value == "delimiter":
+				options.delimiter = ch
+			default:
+				options.quote = ch
+			}
+		case "header":
+			options.header = isTruthy(pair.Value)
+			options.headerSet = true
+		case "quoting":
+			value, ok := pair.Value.(*object.String)
+			if !ok || (value.Value != "minimal" && value.Value != "all" && value.Value != "none") {
+				return options, newErrorOfKind(object.ARGUMENT_ERROR, "quoting of `%s` must be \"minimal\", \"all\" or \"none\", got %s", name, pair.Value.Inspect())
+			}
+			options.quoting = value.Value
+		default:
+			return options, newErrorOfKind(object.ARGUMENT_ERROR, "unknown option of `%s`: %s", name, key.Value)
+		}
+	}
+	if options.delimiter == options.quote {
+		return options, newErrorOfKind(object.ARGUMENT_ERROR, "delimiter and quote of `%s` must differ", name)
+	}
+	if options.quote == 0 && options.quoting != "none" && options.quoting != "minimal" {
+		return options, newErrorOfKind(object.ARGUMENT_ERROR, "quoting %q of `%s` needs a quote", options.quoting, name)
+	}
+	return options, nil
+}
+
+// csvIterator struct reads the rows of CSV one by one
+/*
+	The rows are arrays of strings, or hashes keyed by the fields of the header.
+	The lines are ended by "\n", "\r\n" or "\r", the empty lines are skipped,
+	and a quoted field may contain the delimiters and the newlines, and a doubled quote for a quote.
+*/
+type csvIterator struct {
+	in      io.RuneScanner
+	closer  io.Closer // the file to close at the end, if any
+	options csvOptions
+	header  []string
+	line    int   // the line being read, from 1
+	err     error // the error of reading the input, other than its end
+	done    bool
+}
+
+// newCSVIterator function
+func newCSVIterator(in io.RuneScanner, closer io.Closer, options csvOptions) *csvIterator {
+	return &csvIterator{in: in, closer: closer, options: options, line: 1}
+}
+
+// Next method of csvIterator struct
+func (it *csvIterator) Next() (object.Object, bool) {
+	if it.done {
+		return nil, false
+	}
+	fields, line, err := it.readRow()
+	if err == nil && fields != nil && it.options.header && it.header == nil {
+		it.header = fields
+		fields, line, err = it.readRow()
+	}
+	if it.err != nil { // the row is cut short by the error
+		err = newError("could not read csv at line %d: %s", it.line, it.err)
+	}
+	if err != nil || fields == nil {
+		it.Close()
+		if err != nil {
+			return err, true
+		}
+		return nil, false
+	}
+	if it.header == nil {
+		elements := make([]object.Object, len(fields))
+		for i, field := range fields {
+			elements[i] = &object.String{Value: field}
+		}
+		return object.NewArray(elements), true
+	}
+	if len(fields) != len(it.header) {
+		it.Close()
+		return newError("invalid csv at line %d: %d fields, but the header has %d", line, len(fields), len(it.header)), true
+	}
+	row := object.NewHash()
+	for i, field := range fields {
+		row.Set(&object.String{Value: it.header[i]}, &object.String{Value: field})
+	}
+	return row, true
+}
+
+// Close method of csvIterator struct ends the iteration, and closes the file
+func (it *csvIterator) Close() object.Object {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	if it.closer != nil {
+		if err := it.closer.Close(); err != nil {
+			return newError("%s", err)
+		}
+	}
+	return nil
+}
+
+// readRune method of csvIterator struct reads a rune, counting the lines, and returns false at the end
+// "\r\n" is read as "\n", and an error of reading the input ends it as well, recorded in err
+func (it *csvIterator) readRune() (rune, bool) {
+	r, _, err := it.in.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			it.err = err
+		}
+		return 0, false
+	}
+	if r == '\r' {
+		next, _, err := it.in.ReadRune()
+		switch {
+		case err == nil && next != '\n':
+			it.in.UnreadRune()
+		case err != nil && err != io.EOF:
+			it.err = err
+		}
+		r = '\n'
+	}
+	if r == '\n' {
+		it.line++
+	}
+	return r, true
+}
+
+// readRow method of csvIterator struct reads the fields of the next row, and the line where the row starts
+// the fields are nil at the end of the input
+func (it *csvIterator) readRow() ([]string, int, *object.Error) {
+	r, ok := it.readRune()
+	for ok && r == '\n' { // the empty lines
+		r, ok = it.readRune()
+	}
+	if !ok {
+		return nil, it.line, nil
+	}
+	start := it.line
+	fields := []string{}
+	for {
+		var field strings.Builder
+		if r == it.options.quote && it.options.quote != 0 {
+			quoteLine := it.line
+			for {
+				if r, ok = it.readRune(); !ok {
+					return nil, start, newError("invalid csv at line %d: unterminated quoted field", quoteLine)
+				}
+				if r == it.options.quote {
+					if r, ok = it.readRune(); !ok || r != it.options.quote {
+						break
+					}
+				}
+				field.WriteRune(r)
+			}
+			if ok && r != it.options.delimiter && r != '\n' {
+				return nil, start, newError("invalid csv at line %d: unexpected %q after quoted field", it.line, r)
+			}
+		} else {
+			for ok && r != it.options.delimiter && r != '\n' {
+				field.WriteRune(r)
+				r, ok = it.readRune()
+			}
+		}
+		fields = append(fields, field.String())
+		if !ok || r != it.options.delimiter {
+			return fields, start, nil
+		}
+		r, ok = it.readRune()
+	}
+}
+
+// formatCSV function writes the rows of the iterator
+func formatCSV(iterator object.Iterator, options csvOptions) object.Object {
+	var out strings.Builder
+	var header []object.Object // the keys of the first hash
+	number := 0
+	for row, ok := iterator.Next(); ok; row, ok = iterator.Next() {
+		if isError(row) {
+			return row
+		}
+		number++
+		var fields []object.Object
+		switch row := row.(type) {
+		case *object.Array:
+			fields = row.Elements()
+		case *object.Tuple:
+			fields = row.Elements
+		case *object.Hash:
+			if header == nil {
+				for _, pair := range row.Pairs() {
+					header = append(header, pair.Key)
+				}
+				if !options.headerSet || options.header {
+					if err := writeCSVRow(&out, header, options, number); err != nil {
+						return err
+					}
+				}
+			}
+			for _, pair := range row.Pairs() {
+				if !containsObject(header, pair.Key) {
+					return newError("row %d of csv has the key %s not in the header", number, pair.Key.Inspect())
+				}
+			}
+			fields = make([]object.Object, len(header))
+			for i, key := range header {
+				if value, ok := row.Get(key); ok {
+					fields[i] = value
+				} else {
+					fields[i] = NULL
+				}
+			}
+		default:
+			return newErrorOfKind(object.TYPE_ERROR, "row %d of csv must be ARRAY, TUPLE or HASH, got %s", number, row.Type())
+		}
+		if err := writeCSVRow(&out, fields, options, number); err != nil {
+			return err
+		}
+	}
+	return &object.String{Value: out.String()}
+}
+
+// containsObject function reports whether the objects contain one equal to obj
+func containsObject(objects []object.Object, obj object.Object) bool {
+	for _, o := range objects {
+		if object.Equal(o, obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCSVRow function writes the fields in a line
+// the strings are written as they are, null as the empty field, and the numbers and booleans as they are inspected
+func writeCSVRow(out *strings.Builder, fields []object.Object, options csvOptions, number int) *object.Error {
+	for i, field := range fields {
+		if i > 0 {
+			out.WriteRune(options.delimiter)
+		}
+		var value string
+		switch field := field.(type) {
+		case *object.String:
+			value = field.Value
+		case *object.Null:
+		case *object.Integer, *object.BigInt, *object.Decimal, *object.Boolean:
+			value = field.Inspect()
+		default:
+			return newErrorOfKind(object.TYPE_ERROR, "cannot write %s as csv field in row %d", field.Type(), number)
+		}
+		needsQuote := strings.ContainsAny(value, "\r\n") || strings.ContainsRune(value, options.delimiter) ||
+			(options.quote != 0 && (strings.ContainsRune(value, options.quote) || strings.HasPrefix(value, " ")))
+		switch {
+		case options.quoting == "all" || (options.quoting == "minimal" && needsQuote):
+			if options.quote == 0 {
+				return newError("field %q of row %d of csv needs a quote", value, number)
+			}
+			quote := string(options.quote)
+			out.WriteString(quote)
+			out.WriteString(strings.Replace(value, quote, quote+quote, -1))
+			out.WriteString(quote)
+		case needsQuote:
+			return newError("field %q of row %d of csv needs quoting", value, number)
+		default:
+			out.WriteString(value)
+		}
+	}
+	out.WriteString("\n")
+	return nil
+}
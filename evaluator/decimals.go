@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/object"
+)
+
+// DecimalPrecision is the number of the digits after the decimal point kept by the division of decimals
+var DecimalPrecision = 16
+
+// DecimalRounding is the rounding mode of the division of decimals, and the default mode of round
+var DecimalRounding = object.RoundHalfEven
+
+// maxRoundPlaces is the largest number of places which round accepts, as the decimals keep all the digits
+const maxRoundPlaces = 10000
+
+// decimalBuiltins defines the builtin functions for decimals
+var decimalBuiltins = map[string]*object.Builtin{
+	"decimal": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			if arg, ok := args[0].(*object.String); ok {
+				d, err := object.ParseDecimal(arg.Value)
+				if err != nil {
+					return newError("%s", err)
+				}
+				return d
+			}
+			d, ok := object.ToDecimal(args[0])
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `decimal` not supported, got %s", args[0].Type())
+			}
+			return d
+		},
+	},
+	"round": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2 or 3", len(args))
+			}
+			d, ok := object.ToDecimal(args[0])
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `round` not supported, got %s", args[0].Type())
+			}
+			places, ok := args[1].(*object.Integer)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `round` not supported, got %s", args[1].Type())
+			}
+			if places.Value < 0 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "number of places must not be negative, got %d", places.Value)
+			}
+			if places.Value > maxRoundPlaces {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "number of places must be at most %d, got %d", maxRoundPlaces, places.Value)
+			}
+			mode := DecimalRounding
+			if len(args) == 3 {
+				name, ok := args[2].(*object.String)
+				if !ok {
+					return newErrorOfKind(object.TYPE_ERROR, "argument to `round` not supported, got %s", args[2].Type())
+				}
+				if mode, ok = object.RoundingModes[name.Value]; !ok {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "unknown rounding mode: %s", name.Value)
+				}
+			}
+			return d.Round(int(places.Value), mode)
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range decimalBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// evalDecimalLiteral function
+func evalDecimalLiteral(literal *ast.DecimalLiteral) object.Object {
+	return &object.Decimal{Value: literal.Value, Scale: literal.Scale}
+}
+
+// evalDecimalInfixExpression function
+// this function is called when left and right are numbers, and at least one of them is *object.Decimal type
+func evalDecimalInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.ToDecimal(left)
+	rightVal, _ := object.ToDecimal(right)
+	switch operator {
+	case "+":
+		return leftVal.Add(rightVal)
+	case "-":
+		return leftVal.Sub(rightVal)
+	case "*":
+		return leftVal.Mul(rightVal)
+	case "/":
+		if rightVal.Value.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return leftVal.Quo(rightVal, DecimalPrecision, DecimalRounding)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// isNumber function reports whether obj is an Integer, a BigInt or a Decimal
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.DECIMAL_OBJ
+}
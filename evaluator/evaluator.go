@@ -2,6 +2,9 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"unicode/utf8"
 
 	"github.com/BOBO1997/monkey/ast"
 	"github.com/BOBO1997/monkey/object"
@@ -21,102 +24,221 @@ var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments, got=%d, want=1", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Bytes:
 				return &object.Integer{Value: int64(len(arg.Value))}
 			case *object.Array:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Range:
+				return normalizeBigInt(arg.BigLen())
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Tuple:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			default:
-				return newError("argument to `len` not supported, got %s", args[0].Type())
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `len` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"first": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments, got=%d, want=1", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
+				if arg.Value != "" {
+					r, _ := utf8.DecodeRuneInString(arg.Value)
+					return &object.String{Value: string(r)}
+				}
+				return NULL
+			case *object.Bytes:
 				if len(arg.Value) > 0 {
-					return &object.String{Value: arg.Value[0:1]}
+					return &object.Integer{Value: int64(arg.Value[0])}
 				}
 				return NULL
 			case *object.Array:
-				if len(arg.Elements) > 0 {
-					return arg.Elements[0]
+				if arg.Len() > 0 {
+					return arg.At(0)
+				}
+				return NULL
+			case *object.Range:
+				if !arg.Empty() {
+					return &object.Integer{Value: arg.At(0)}
 				}
 				return NULL
 			default:
-				return newError("argument to `first` not supported, got %s", args[0].Type())
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `first` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"last": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments, got=%d, want=1", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
+				if arg.Value != "" {
+					r, _ := utf8.DecodeLastRuneInString(arg.Value)
+					return &object.String{Value: string(r)}
+				}
+				return NULL
+			case *object.Bytes:
 				length := len(arg.Value)
 				if length > 0 {
-					return &object.String{Value: arg.Value[length-1 : length]}
+					return &object.Integer{Value: int64(arg.Value[length-1])}
 				}
 				return NULL
 			case *object.Array:
-				length := len(arg.Elements)
+				length := arg.Len()
 				if length > 0 {
-					return arg.Elements[length-1]
+					return arg.At(length - 1)
+				}
+				return NULL
+			case *object.Range:
+				if !arg.Empty() {
+					return &object.Integer{Value: arg.Last()}
 				}
 				return NULL
 			default:
-				return newError("argument to `last` not supported, got %s", args[0].Type())
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `last` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"rest": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments, got=%d, want=1", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				length := len(arg.Value)
-				if length > 0 {
-					return &object.String{Value: arg.Value[1:length]}
+				if arg.Value != "" {
+					_, size := utf8.DecodeRuneInString(arg.Value)
+					return &object.String{Value: arg.Value[size:]}
+				}
+				return NULL
+			case *object.Bytes:
+				if len(arg.Value) > 0 {
+					return &object.Bytes{Value: arg.Value[1:]}
 				}
 				return NULL
 			case *object.Array:
-				length := len(arg.Elements)
-				if length > 0 {
-					rest := make([]object.Object, length-1, length-1)
-					copy(rest, arg.Elements[1:length])
-					return &object.Array{Elements: rest}
+				elements := arg.Elements()
+				if len(elements) > 0 {
+					return object.NewArray(elements[1:])
+				}
+				return NULL
+			case *object.Range:
+				if !arg.Empty() {
+					return arg.Rest()
 				}
 				return NULL
 			default:
-				return newError("argument to `rest` not supported, got %s", args[0].Type())
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `rest` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"push": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("wrong number of arguments, got=%d, want=2", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Array:
+				return arg.Push(args[1])
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `push` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"assoc": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=3", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Array:
+				index, ok := args[1].(*object.Integer)
+				if !ok {
+					return newErrorOfKind(object.TYPE_ERROR, "index to `assoc` must be INTEGER, got %s", args[1].Type())
+				}
+				if index.Value < 0 || int64(arg.Len()) <= index.Value {
+					return newError("index out of range: %d", index.Value)
+				}
+				return arg.Set(int(index.Value), args[2])
+			case *object.Hash:
+				hash, ok := arg.Assoc(args[1], args[2])
+				if !ok {
+					return newErrorOfKind(object.TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
+				}
+				return hash
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `assoc` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"array": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.Array:
-				length := len(arg.Elements)
-				newArr := make([]object.Object, length+1, length+1)
-				copy(newArr, arg.Elements)
-				newArr[length] = args[1]
-				return &object.Array{Elements: newArr}
+				return arg
+			case *object.Range:
+				elements, err := arg.Elements()
+				if err != nil {
+					return newError("%s", err)
+				}
+				return object.NewArray(elements)
+			case object.Iterable:
+				elements, err := collectElements(arg.Iterator())
+				if err != nil {
+					return err
+				}
+				return object.NewArray(elements)
 			default:
-				return newError("argument to `push` not supported, got %s", args[0].Type())
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `array` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"set": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
 			}
+			iterable, ok := args[0].(object.Iterable)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `set` not supported, got %s", args[0].Type())
+			}
+			elements, err := collectElements(iterable.Iterator())
+			if err != nil {
+				return err
+			}
+			return newSet(elements)
+		},
+	},
+	"is": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			var variant *object.Variant
+			switch arg := args[1].(type) {
+			case *object.Variant:
+				variant = arg
+			case *object.EnumValue:
+				variant = arg.Variant
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `is` must be VARIANT, got %s", args[1].Type())
+			}
+			value, ok := args[0].(*object.EnumValue)
+			return nativeBoolToBooleanObject(ok && value.Variant == variant)
 		},
 	},
 	"puts": &object.Builtin{
@@ -130,7 +252,7 @@ var builtins = map[string]*object.Builtin{
 	"__inspect_env__": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) > 1 {
-				return newError("wrong number of arguments, got=%d, want= 0 or 1", len(args))
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want= 0 or 1", len(args))
 			}
 			if len(args) == 0 {
 				fmt.Println(object.InspectEnvironment(BaseEnv))
@@ -154,10 +276,20 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return evalBigIntegerLiteral(node)
+	case *ast.DecimalLiteral:
+		return evalDecimalLiteral(node)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.BytesLiteral:
+		return evalBytesLiteral(node)
+	case *ast.RegexLiteral:
+		return evalRegexLiteral(node)
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return NULL
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
@@ -169,13 +301,19 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if node.Operator == "??" { // the right operand is evaluated only when needed
+			if left != NULL {
+				return left
+			}
+			return Eval(node.Right, env)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
 	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
+		return evalBlockStatement(node, object.NewEnclosedEnvironment(env)) // a block introduces a lexical scope
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 	case *ast.ReturnStatement:
@@ -184,18 +322,50 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.ThrowStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return &object.Error{Message: val.Inspect(), Kind: object.THROWN_ERROR, Value: val}
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.DeferStatement:
+		return evalDeferStatement(node, env)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env)
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
+	case *ast.UnpackStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if err := evalUnpackStatement(node, val, env); err != nil {
+			return err
+		}
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
 		params := node.Parameters // raw ast
 		body := node.Body         // raw ast
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		return &object.Function{Name: node.Name, Parameters: params, Body: body, Env: env, IsGenerator: node.IsGenerator}
+	case *ast.FunctionStatement:
+		return nil // already defined by hoistFunctionStatements
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, field.Value)
+		}
+		env.Set(node.Name.Value, &object.Struct{Name: node.Name.Value, Fields: fields})
+	case *ast.EnumStatement:
+		env.Set(node.Name.Value, newEnum(node))
+	case *ast.UpdateExpression:
+		return evalUpdateExpression(node, env)
 	case *ast.CallExpression:
 		/*
 			This can handle recursive function
@@ -206,7 +376,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			>> let f = fn(x) { if (x > 0) { puts(x); g(x-1); } else { puts(x); }; };
 			>> let g = fn(x) { if (x > 0) { puts(x); f(x-1); } else { puts(x); }; };
 
-			However, mutual recurrence should be defined by a new syntax.
+			Mutual recursion inside one block is defined by function declarations,
+			which are hoisted so that the order of the declarations does not matter:
+			>> fn isEven(x) { if (x == 0) { true } else { isOdd(x - 1) } }
+			>> fn isOdd(x) { if (x == 0) { false } else { isEven(x - 1) } }
 		*/
 		if node.Function.TokenLiteral() == "quote" {
 			return quote(node.Arguments[0], env)
@@ -219,18 +392,25 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		result := applyFunction(function, args)
+		if err, ok := result.(*object.Error); ok {
+			err.Stack = append(err.Stack, functionName(function, node.Function)) // record the frame the error went through
+		}
+		return result
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) { // if error occur
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return object.NewArray(elements)
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
 		index := Eval(node.Index, env)
 		if isError(index) {
 			return index
@@ -238,6 +418,18 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIndexExpression(left, index)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Tuple{Elements: elements}
+	case *ast.SetLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return newSet(elements)
 	}
 
 	return nil
@@ -246,6 +438,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 // evalProgram function
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
+	hoistFunctionStatements(program.Statements, env)
 	for _, statement := range program.Statements {
 		result = Eval(statement, env)
 		switch result := result.(type) {
@@ -259,8 +452,14 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 }
 
 // evalBlockStatement function
+/*
+	The statements are evaluated directly in env, without introducing a new scope.
+	Eval encloses env before calling this for any block, while function and macro bodies call this directly,
+	since the environment of the call is already a new scope.
+*/
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
+	hoistFunctionStatements(block.Statements, env)
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 		if result != nil {
@@ -273,6 +472,16 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
+// hoistFunctionStatements function defines the function declarations of the statements before evaluating them
+// every declared function encloses env, so that the functions can call each other regardless of the order
+func hoistFunctionStatements(stmts []ast.Statement, env *object.Environment) {
+	for _, stmt := range stmts {
+		if fs, ok := stmt.(*ast.FunctionStatement); ok {
+			env.Set(fs.Name.Value, Eval(fs.Function, env))
+		}
+	}
+}
+
 // evalStatements function
 func evalStatements(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
@@ -302,7 +511,7 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "-":
 		return evalMinusOperatorExpression(right)
 	default:
-		return newError("unknown operator: %s %s", operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s", operator, right.Type())
 	}
 }
 
@@ -319,13 +528,29 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 // evalMinusOperatorExpression function
-// this function is only called when right is *object.Integer type
+// integers are promoted to BigInt on overflow, as in evalIntegerArithmetic
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ { // ok; type assersion should have error checking
-		return newError("unknown operator: -%s", right.Type())
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value != math.MinInt64 {
+			return &object.Integer{Value: -right.Value}
+		}
+		if OverflowPolicy == TrapOnOverflow {
+			return newErrorOfKind(object.OVERFLOW_ERROR, "integer overflow: -(%d)", right.Value)
+		}
+		return &object.BigInt{Value: new(big.Int).Neg(big.NewInt(right.Value))}
+	case *object.BigInt:
+		return normalizeBigInt(new(big.Int).Neg(right.Value))
+	case *object.Decimal:
+		return right.Neg()
+	case *object.Duration:
+		if right.Value == minDuration {
+			return newErrorOfKind(object.OVERFLOW_ERROR, "duration overflow: -(%s)", right.Inspect())
+		}
+		return &object.Duration{Value: -right.Value}
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value // ok; type assersion should have error checking
-	return &object.Integer{Value: -value}
 }
 
 // evalInfixExpression function
@@ -333,32 +558,143 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ: // evaluated first
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalDecimalInfixExpression(operator, left, right)
 	/*
 		case left.Type() != right.Type():
-			return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+			return newErrorOfKind(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	*/
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ: // evaluated first
 		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.BYTES_OBJ && right.Type() == object.BYTES_OBJ:
+		return evalBytesInfixExpression(operator, left, right)
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ,
+		left.Type() == object.TUPLE_OBJ && right.Type() == object.TUPLE_OBJ:
+		return evalArrayInfixExpression(operator, left, right)
+	case left.Type() == object.SET_OBJ && right.Type() == object.SET_OBJ:
+		return evalSetInfixExpression(operator, left, right)
+	case isTimeOrDuration(left) || isTimeOrDuration(right):
+		if operator == "==" || operator == "!=" {
+			return nativeBoolToBooleanObject(object.Equal(left, right) == (operator == "=="))
+		}
+		return evalTimeInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(object.Equal(left, right))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
 // evalStringInfixExpression function
 // this function is only called when left and right are both *object.String type
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	if operator != "+" {
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
-	}
 	leftVal := left.(*object.String).Value   // ok; type assersion should have error checking
 	rightVal := right.(*object.String).Value // ok; type assersion should have error checking
-	return &object.String{Value: leftVal + rightVal}
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	case "<", ">", "<=", ">=":
+		return evalComparison(operator, left, right)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalArrayInfixExpression function
+// arrays (and tuples) are equal if their elements are, and ordered lexicographically
+func evalArrayInfixExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	case "<", ">", "<=", ">=":
+		return evalComparison(operator, left, right)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalSetInfixExpression function
+// | is the union, & is the intersection and - is the difference of the sets
+func evalSetInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Set)
+	rightVal := right.(*object.Set)
+	switch operator {
+	case "|":
+		return leftVal.Union(rightVal)
+	case "&":
+		return leftVal.Intersection(rightVal)
+	case "-":
+		return leftVal.Difference(rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalComparison function applies an ordering operator by object.Compare
+func evalComparison(operator string, left, right object.Object) object.Object {
+	order, ok := object.Compare(left, right)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "unordered operands: %s", describeUnordered(operator, left, right))
+	}
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(order < 0)
+	case ">":
+		return nativeBoolToBooleanObject(order > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(order <= 0)
+	default:
+		return nativeBoolToBooleanObject(order >= 0)
+	}
+}
+
+// describeUnordered function names the types of the operands which are not ordered,
+// followed by the first elements not ordered for arrays and tuples, such as ARRAY < ARRAY (element 0: INTEGER < STRING)
+func describeUnordered(operator string, left, right object.Object) string {
+	description := fmt.Sprintf("%s %s %s", left.Type(), operator, right.Type())
+	var leftElements, rightElements []object.Object
+	switch left := left.(type) {
+	case *object.Array:
+		right, ok := right.(*object.Array)
+		if !ok {
+			return description
+		}
+		leftElements, rightElements = left.Elements(), right.Elements()
+	case *object.Tuple:
+		right, ok := right.(*object.Tuple)
+		if !ok {
+			return description
+		}
+		leftElements, rightElements = left.Elements, right.Elements
+	default:
+		return description
+	}
+	for i := 0; i < len(leftElements) && i < len(rightElements); i++ {
+		order, ok := object.Compare(leftElements[i], rightElements[i])
+		if !ok {
+			return fmt.Sprintf("%s (element %d: %s)", description, i, describeUnordered(operator, leftElements[i], rightElements[i]))
+		}
+		if order != 0 {
+			break
+		}
+	}
+	return description
 }
 
 // evalIntegerInfixExpression function
@@ -367,14 +703,8 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	leftVal := left.(*object.Integer).Value   // ok; type assersion should have error checking
 	rightVal := right.(*object.Integer).Value // ok; type assersion should have error checking
 	switch operator {
-	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+	case "+", "-", "*", "/":
+		return evalIntegerArithmetic(operator, left.(*object.Integer), right.(*object.Integer))
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -387,8 +717,12 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return nativeBoolToBooleanObject(leftVal <= rightVal)
 	case ">=":
 		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "..":
+		return &object.Range{Start: leftVal, End: rightVal}
+	case "..=":
+		return &object.Range{Start: leftVal, End: rightVal, Inclusive: true}
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -407,6 +741,47 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	return NULL
 }
 
+// evalTryExpression function
+/*
+	An error raised in the try block is caught by the catch block, with the caught value bound to the catch parameter.
+	The finally block is always evaluated afterwards, and its result is discarded unless it returns or raises an error.
+*/
+func evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(te.Block, env)
+	if err, ok := result.(*object.Error); ok && te.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(te.CatchParam.Value, caughtValue(err))
+		result = Eval(te.Catch, catchEnv)
+	}
+	if te.Finally != nil {
+		finally := Eval(te.Finally, env)
+		if finally != nil {
+			ft := finally.Type()
+			if ft == object.RETURN_VALUE_OBJ || ft == object.ERROR_OBJ {
+				return finally
+			}
+		}
+	}
+	return result
+}
+
+// caughtValue function converts an error to the value bound by a catch block
+// thrown values are passed as they are, and the other errors become a hash with "message", "kind" and "stack"
+func caughtValue(err *object.Error) object.Object {
+	if err.Value != nil {
+		return err.Value
+	}
+	stack := make([]object.Object, len(err.Stack))
+	for i, frame := range err.Stack {
+		stack[i] = &object.String{Value: frame}
+	}
+	hash := object.NewHash()
+	hash.Set(&object.String{Value: "message"}, &object.String{Value: err.Message})
+	hash.Set(&object.String{Value: "kind"}, &object.String{Value: err.Kind})
+	hash.Set(&object.String{Value: "stack"}, object.NewArray(stack))
+	return hash
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -421,7 +796,11 @@ func isTruthy(obj object.Object) bool {
 }
 
 func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	return newErrorOfKind(object.RUNTIME_ERROR, format, a...)
+}
+
+func newErrorOfKind(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind}
 }
 
 func isError(obj object.Object) bool {
@@ -438,12 +817,20 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if builtin, ok := builtins[node.Value]; ok {
 		return builtin
 	}
-	return newError("identifier not found: " + node.Value)
+	return newErrorOfKind(object.NAME_ERROR, "identifier not found: "+node.Value)
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 	for _, e := range exps {
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			elements := evalSpreadExpression(spread, env)
+			if len(elements) == 1 && isError(elements[0]) {
+				return elements
+			}
+			result = append(result, elements...)
+			continue
+		}
 		evaluated := Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
@@ -453,26 +840,116 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
+// evalSpreadExpression function returns the elements spliced by a spread expression in a list
+func evalSpreadExpression(spread *ast.SpreadExpression, env *object.Environment) []object.Object {
+	value := Eval(spread.Value, env)
+	if isError(value) {
+		return []object.Object{value}
+	}
+	switch value := value.(type) {
+	case *object.Array:
+		return value.Elements()
+	case *object.Range:
+		elements, err := value.Elements()
+		if err != nil {
+			return []object.Object{newError("%s", err)}
+		}
+		return elements
+	case object.Iterable:
+		elements, err := collectElements(value.Iterator())
+		if err != nil {
+			return []object.Object{err}
+		}
+		return elements
+	default:
+		return []object.Object{newErrorOfKind(object.TYPE_ERROR, "spread of %s not supported in a list", value.Type())}
+	}
+}
+
 func applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(args) != len(fn.Parameters) {
+			if fn.Name != "" {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments to `%s`, got=%d, want=%d", fn.Name, len(args), len(fn.Parameters))
+			}
+			return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=%d", len(args), len(fn.Parameters))
+		}
 		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
+		if fn.IsGenerator {
+			return newGenerator(fn, extendedEnv)
+		}
+		evaluated := evalBlockStatement(fn.Body, extendedEnv)
+		evaluated = runDeferredCalls(extendedEnv.Frame(), evaluated)
 		return unwrapReturnValues(evaluated)
 	case *object.Builtin:
 		return fn.Fn(args...)
+	case *object.Struct:
+		if len(args) != len(fn.Fields) {
+			return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments to `%s`, got=%d, want=%d", fn.Name, len(args), len(fn.Fields))
+		}
+		return &object.Record{Struct: fn, Values: args}
+	case *object.Variant:
+		if len(args) != len(fn.Fields) {
+			return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments to `%s.%s`, got=%d, want=%d", fn.Enum.Name, fn.Name, len(args), len(fn.Fields))
+		}
+		return &object.EnumValue{Variant: fn, Values: args}
+	}
+	return newErrorOfKind(object.TYPE_ERROR, "not a function: %s", fn.Type())
+}
+
+// functionName function returns the name of the called function shown in stack traces
+func functionName(fn object.Object, callee ast.Expression) string {
+	if fn, ok := fn.(*object.Function); ok {
+		if fn.Name != "" {
+			return fn.Name
+		}
+		return "<anonymous>"
 	}
-	return newError("not a function: %s", fn.Type())
+	return callee.String()
 }
 
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+	env := object.NewFunctionEnvironment(fn.Env)
 	for paramIdx, param := range fn.Parameters {
 		env.Set(param.Value, args[paramIdx])
 	}
 	return env
 }
 
+// evalDeferStatement function evaluates the function and the arguments of the deferred call
+// the call itself is applied by runDeferredCalls when the enclosing function call returns
+func evalDeferStatement(ds *ast.DeferStatement, env *object.Environment) object.Object {
+	frame := env.Frame()
+	if frame == nil {
+		return newError("defer outside function")
+	}
+	function := Eval(ds.Call.Function, env)
+	if isError(function) {
+		return function
+	}
+	args := evalExpressions(ds.Call.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+	frame.Deferred = append(frame.Deferred, &object.DeferredCall{Fn: function, Args: args})
+	return nil
+}
+
+// runDeferredCalls function applies the deferred calls of the frame in LIFO order
+// an error raised by a deferred call replaces the result, unless the function has already failed
+func runDeferredCalls(frame *object.Frame, result object.Object) object.Object {
+	for i := len(frame.Deferred) - 1; i >= 0; i = i - 1 {
+		deferred := frame.Deferred[i]
+		evaluated := applyFunction(deferred.Fn, deferred.Args)
+		if isError(evaluated) && !isError(result) {
+			result = evaluated
+		}
+	}
+	frame.Deferred = nil
+	return result
+}
+
 func unwrapReturnValues(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok { // ok; type assersion should have error checking
 		return returnValue.Value
@@ -486,10 +963,32 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalApplyIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalTupleIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.BYTES_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalBytesIndexExpression(left, index)
+	case left.Type() == object.SET_OBJ:
+		return evalSetIndexExpression(left, index)
+	case left.Type() != object.HASH_OBJ && index.Type() == object.RANGE_OBJ:
+		return evalSliceExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.RECORD_OBJ:
+		return evalRecordIndexExpression(left, index)
+	case left.Type() == object.TIME_OBJ:
+		return evalTimeIndexExpression(left, index)
+	case left.Type() == object.DURATION_OBJ:
+		return evalDurationIndexExpression(left, index)
+	case left.Type() == object.ENUM_OBJ:
+		return evalEnumIndexExpression(left, index)
+	case left.Type() == object.ENUM_VALUE_OBJ:
+		return evalEnumValueIndexExpression(left, index)
 	default:
-		return newError("index operator not supported: %s", left.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "index operator not supported: %s", left.Type())
 	}
 }
 
@@ -497,51 +996,258 @@ func evalIndexExpression(left, index object.Object) object.Object {
 func evalApplyIndexExpression(array, index object.Object) object.Object {
 	arrayObject, ok := array.(*object.Array) // ok; type assersion should have error checking
 	if !ok {
-		return newError("not Array: got=%s", array.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "not Array: got=%s", array.Type())
 	}
 	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-	if idx < 0 || max < idx {
+	if idx < 0 || int64(arrayObject.Len()) <= idx {
 		return NULL
 	}
-	return arrayObject.Elements[idx]
+	return arrayObject.At(int(idx))
 }
 
-// evalHashLiteral function makes a hash object, which contains a map from object to object
+// evalTupleIndexExpression function accesses the index-th element of the tuple
+func evalTupleIndexExpression(tuple, index object.Object) object.Object {
+	elements := tuple.(*object.Tuple).Elements
+	idx := index.(*object.Integer).Value
+	if idx < 0 || int64(len(elements)) <= idx {
+		return NULL
+	}
+	return elements[idx]
+}
+
+// evalUnpackStatement function binds the elements of the tuple (or the array) to the names
+func evalUnpackStatement(node *ast.UnpackStatement, val object.Object, env *object.Environment) *object.Error {
+	var elements []object.Object
+	switch val := val.(type) {
+	case *object.Tuple:
+		elements = val.Elements
+	case *object.Array:
+		elements = val.Elements()
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "cannot unpack %s", val.Type())
+	}
+	if len(elements) != len(node.Names) {
+		return newErrorOfKind(object.TYPE_ERROR, "cannot unpack %d values into %d names", len(elements), len(node.Names))
+	}
+	for i, name := range node.Names {
+		env.Set(name.Value, elements[i])
+	}
+	return nil
+}
+
+// evalRangeIndexExpression function accesses the index-th element of the range
+func evalRangeIndexExpression(rng, index object.Object) object.Object {
+	rangeObject := rng.(*object.Range)
+	idx := index.(*object.Integer).Value
+	if !rangeObject.Contains(idx) {
+		return NULL
+	}
+	return &object.Integer{Value: rangeObject.At(idx)}
+}
+
+// evalSliceExpression function slices an array, a tuple, a string, bytes or a range by a range of indices
+// the indices out of bounds are clamped, as slicing never fails
+func evalSliceExpression(left, index object.Object) object.Object {
+	bounds := index.(*object.Range)
+	clamp := func(length int64) (int64, int64) {
+		low := bounds.Start
+		high := bounds.End // exclusive, and never below Start
+		if bounds.Inclusive && high < math.MaxInt64 {
+			high++ // ..=9223372036854775807 is beyond every length anyway
+		}
+		if low < 0 {
+			low = 0
+		}
+		if length < low {
+			low = length
+		}
+		if high < low {
+			high = low
+		}
+		if length < high {
+			high = length
+		}
+		return low, high
+	}
+	switch left := left.(type) {
+	case *object.Array:
+		low, high := clamp(int64(left.Len()))
+		return object.NewArray(left.Elements()[low:high])
+	case *object.Tuple:
+		low, high := clamp(int64(len(left.Elements)))
+		elements := make([]object.Object, high-low, high-low)
+		copy(elements, left.Elements[low:high])
+		return &object.Tuple{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		low, high := clamp(int64(len(runes)))
+		return &object.String{Value: string(runes[low:high])}
+	case *object.Bytes:
+		low, high := clamp(int64(len(left.Value)))
+		return &object.Bytes{Value: left.Value[low:high]}
+	case *object.Range:
+		return left.Slice(bounds)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "slice operator not supported: %s", left.Type())
+	}
+}
+
+// evalRecordIndexExpression function accesses the field of the record
+// unlike hashes, accessing an unknown field is an error
+func evalRecordIndexExpression(record, index object.Object) object.Object {
+	recordObject := record.(*object.Record)
+	name, ok := index.(*object.String)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "field name must be STRING, got %s", index.Type())
+	}
+	value, ok := recordObject.Get(name.Value)
+	if !ok {
+		return newErrorOfKind(object.NAME_ERROR, "unknown field `%s` of %s", name.Value, recordObject.Struct.Name)
+	}
+	return value
+}
+
+// newEnum function creates the enum of the declaration
+// a variant carrying nothing gets its only value in advance, so that it is compared and hashed like the others
+func newEnum(es *ast.EnumStatement) *object.Enum {
+	enum := &object.Enum{Name: es.Name.Value}
+	for _, v := range es.Variants {
+		variant := &object.Variant{Enum: enum, Name: v.Name.Value}
+		for _, field := range v.Fields {
+			variant.Fields = append(variant.Fields, field.Value)
+		}
+		if len(variant.Fields) == 0 {
+			variant.Unit = &object.EnumValue{Variant: variant}
+		}
+		enum.Variants = append(enum.Variants, variant)
+	}
+	return enum
+}
+
+// evalEnumIndexExpression function accesses the variant of the enum
+// a variant carrying nothing evaluates to its value, and the others to their constructors
+func evalEnumIndexExpression(enum, index object.Object) object.Object {
+	enumObject := enum.(*object.Enum)
+	name, ok := index.(*object.String)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "variant name must be STRING, got %s", index.Type())
+	}
+	variant, ok := enumObject.Variant(name.Value)
+	if !ok {
+		return newErrorOfKind(object.NAME_ERROR, "unknown variant `%s` of %s", name.Value, enumObject.Name)
+	}
+	if variant.Unit != nil {
+		return variant.Unit
+	}
+	return variant
+}
+
+// evalEnumValueIndexExpression function accesses the payload of the enum value
+func evalEnumValueIndexExpression(value, index object.Object) object.Object {
+	enumValue := value.(*object.EnumValue)
+	name, ok := index.(*object.String)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "field name must be STRING, got %s", index.Type())
+	}
+	payload, ok := enumValue.Get(name.Value)
+	if !ok {
+		return newErrorOfKind(object.NAME_ERROR, "unknown field `%s` of %s.%s", name.Value, enumValue.Variant.Enum.Name, enumValue.Variant.Name)
+	}
+	return payload
+}
+
+// evalUpdateExpression function copies the record, replacing the given fields
+func evalUpdateExpression(ue *ast.UpdateExpression, env *object.Environment) object.Object {
+	evaluated := Eval(ue.Record, env)
+	if isError(evaluated) {
+		return evaluated
+	}
+	record, ok := evaluated.(*object.Record)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "with operator not supported: %s", evaluated.Type())
+	}
+	values := make([]object.Object, len(record.Values), len(record.Values))
+	copy(values, record.Values)
+	for i, field := range ue.Fields {
+		idx := record.Struct.FieldIndex(field.Value)
+		if idx < 0 {
+			return newErrorOfKind(object.NAME_ERROR, "unknown field `%s` of %s", field.Value, record.Struct.Name)
+		}
+		value := Eval(ue.Values[i], env)
+		if isError(value) {
+			return value
+		}
+		values[idx] = value
+	}
+	return &object.Record{Struct: record.Struct, Values: values}
+}
+
+// evalHashLiteral function makes a hash object, evaluating the keys, the values and the spreads in source order
+// a later pair overwrites the value of an earlier one with the same key, but keeps its position
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	for keyExpr, itemExpr := range node.Pairs {
-		key := Eval(keyExpr, env)
+	pairs := object.NewHash()
+	for _, pair := range node.Pairs {
+		if pair.Spread != nil {
+			value := Eval(pair.Spread.Value, env)
+			if isError(value) {
+				return value
+			}
+			hash, ok := value.(*object.Hash)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "spread of %s not supported in a hash", value.Type())
+			}
+			for _, spread := range hash.Pairs() {
+				pairs.Set(spread.Key, spread.Value)
+			}
+			continue
+		}
+		key := Eval(pair.Key, env)
 		if isError(key) {
 			return key
 		}
-		hashKey, ok := key.(object.Hashable) // ok; type assersion should have error checking
-		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+		if _, ok := object.HashKeyOf(key); !ok {
+			return newErrorOfKind(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
 		}
-		item := Eval(itemExpr, env)
+		item := Eval(pair.Value, env)
 		if isError(item) {
 			return item
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: item}
+		pairs.Set(key, item)
 	}
-	return &object.Hash{Pairs: pairs}
+	return pairs
+}
+
+// newSet function creates a set of the elements, dropping the duplicates
+func newSet(elements []object.Object) object.Object {
+	set := object.NewSet()
+	for _, element := range elements {
+		if !set.Add(element) { // the first of the equal elements is kept
+			return newErrorOfKind(object.TYPE_ERROR, "unusable as set element: %s", element.Type())
+		}
+	}
+	return set
+}
+
+// evalSetIndexExpression function reports whether the set contains the index
+func evalSetIndexExpression(set, index object.Object) object.Object {
+	if _, ok := object.HashKeyOf(index); !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "unusable as set element: %s", index.Type())
+	}
+	return nativeBoolToBooleanObject(set.(*object.Set).Has(index))
 }
 
 // evalHashIndexExpression function
 func evalHashIndexExpression(left, index object.Object) object.Object {
 	hashObject, ok := left.(*object.Hash) // ok; type assersion should have error checking
 	if !ok {
-		return newError("not Hash: got=%s", left.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "not Hash: got=%s", left.Type())
 	}
-	key, ok := index.(object.Hashable) // ok; type assersion should have error checking
-	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+	if _, ok := object.HashKeyOf(index); !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
 	}
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	value, ok := hashObject.Get(index)
 	if !ok {
 		return NULL
 	}
-	return pair.Value
+	return value
 }
@@ -1,7 +1,15 @@
 package evaluator
 
 import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/BOBO1997/monkey/lexer"
 	"github.com/BOBO1997/monkey/object"
@@ -151,6 +159,48 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	return true
 }
 
+// errorMessage is the expected result of a program failing with the message
+type errorMessage string
+
+// testObject function checks the result of the program input
+/*
+	expected is an int, a bool or nil for an integer, a boolean or null, an errorMessage for an error,
+	and a string for the Inspect of any other object.
+	So a string never matches an integer, a boolean, null or an error, whose Inspect looks the same.
+*/
+func testObject(t *testing.T, input string, obj object.Object, expected interface{}) bool {
+	ok := false
+	switch expected := expected.(type) {
+	case nil:
+		ok = obj == NULL
+	case int:
+		integer, isInteger := obj.(*object.Integer)
+		ok = isInteger && integer.Value == int64(expected)
+	case bool:
+		boolean, isBoolean := obj.(*object.Boolean)
+		ok = isBoolean && boolean.Value == expected
+	case errorMessage:
+		errObj, isError := obj.(*object.Error)
+		ok = isError && errObj.Message == string(expected)
+	case string:
+		switch obj.(type) {
+		case *object.Integer, *object.Boolean, *object.Null, *object.Error, nil:
+		default:
+			ok = obj.Inspect() == expected
+		}
+	default:
+		t.Fatalf("unsupported expected result for %q: %T", input, expected)
+	}
+	if !ok {
+		got := "nil"
+		if obj != nil {
+			got = fmt.Sprintf("%s %q", obj.Type(), obj.Inspect())
+		}
+		t.Errorf("wrong result for %q. got=%s, want=%#v", input, got, expected)
+	}
+	return ok
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -340,3 +390,1694 @@ ourFunction(20) + first + second;`
 
 	testIntegerObject(t, testEval(input), 70)
 }
+
+func TestTryCatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"try { 1 } catch (e) { 2 }", 1},
+		{"try { throw 5; 1 } catch (e) { e * 2 }", 10},
+		{`try { throw "oops"; } catch (e) { e }`, "oops"},
+		{"try { 1 + true } catch (e) { 2 }", 2},
+		{"let f = fn() { throw 3; }; try { f() } catch (e) { e + 1 }", 4},
+		{"try { try { throw 1; } catch (e) { throw e + 1; } } catch (e) { e + 1 }", 3},
+		{"let f = fn() { try { return 1; } finally { 2 } }; f()", 1},
+		{"let f = fn() { try { return 1; } finally { return 2; } }; f()", 2},
+		{"let f = fn() { try { throw 1; } catch (e) { return e + 10; }; 0 }; f()", 11},
+		{"try { throw 1; } catch (e) { let x = e; }; x", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			switch result := evaluated.(type) {
+			case *object.String:
+				if result.Value != expected {
+					t.Errorf("String has wrong value. got=%q, want=%q", result.Value, expected)
+				}
+			case *object.Error:
+				if result.Message != expected {
+					t.Errorf("wrong error message. got=%q, want=%q", result.Message, expected)
+				}
+			default:
+				t.Errorf("object is neither String nor Error. got=%T (%+v)", evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestFinallyIsAlwaysEvaluated(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`try { 1 } finally { throw "after success"; }`, "after success"},
+		{`try { throw 1; } catch (e) { 2 } finally { throw "after catch"; }`, "after catch"},
+		{`try { throw 1; } finally { throw "after uncaught"; }`, "after uncaught"},
+		{`try { 1 + true } finally { 2 }`, "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	evaluated := testEval(`let f = fn() { throw "boom"; }; f(); 5`)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Kind != object.THROWN_ERROR {
+		t.Errorf("wrong error kind. got=%q", errObj.Kind)
+	}
+	if errObj.Message != "boom" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
+func TestCaughtBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedKind    string
+		expectedStack   string
+	}{
+		{
+			"try { len(1, 2) } catch (e) { e }",
+			"wrong number of arguments, got=2, want=1",
+			object.ARGUMENT_ERROR,
+			"[len]",
+		},
+		{
+			"let f = fn(x) { x }; try { f() } catch (e) { e }",
+			"wrong number of arguments to `f`, got=0, want=1",
+			object.ARGUMENT_ERROR,
+			"[f]",
+		},
+		{
+			"let inner = fn() { 1 + true }; let outer = fn() { inner() }; try { outer() } catch (e) { e }",
+			"type mismatch: INTEGER + BOOLEAN",
+			object.TYPE_ERROR,
+			"[inner, outer]",
+		},
+		{
+			"try { foobar } catch (e) { e }",
+			"identifier not found: foobar",
+			object.NAME_ERROR,
+			"[]",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		hash, ok := evaluated.(*object.Hash)
+		if !ok {
+			t.Fatalf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
+		}
+		get := func(key string) string {
+			value, ok := hash.Get(&object.String{Value: key})
+			if !ok {
+				t.Errorf("no %q in caught error %s", key, hash.Inspect())
+				return ""
+			}
+			return value.Inspect()
+		}
+		if message := get("message"); message != tt.expectedMessage {
+			t.Errorf("wrong message. got=%q, want=%q", message, tt.expectedMessage)
+		}
+		if kind := get("kind"); kind != tt.expectedKind {
+			t.Errorf("wrong kind. got=%q, want=%q", kind, tt.expectedKind)
+		}
+		if stack := get("stack"); stack != tt.expectedStack {
+			t.Errorf("wrong stack. got=%q, want=%q", stack, tt.expectedStack)
+		}
+	}
+}
+
+func TestDeferStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		result   interface{}
+	}{
+		{
+			"let f = fn() { defer record(1); defer record(2); record(3); 4 }; f()",
+			[]string{"3", "2", "1"},
+			4,
+		},
+		{
+			"let f = fn(x) { defer record(x); let x = x + 1; defer record(x); x }; f(1)",
+			[]string{"2", "1"},
+			2,
+		},
+		{
+			"let f = fn() { defer record(1); if (true) { return 2; }; record(3); }; f()",
+			[]string{"1"},
+			2,
+		},
+		{
+			"let f = fn() { defer record(1); 1 + true; record(2); }; f()",
+			[]string{"1"},
+			errorMessage("type mismatch: INTEGER + BOOLEAN"),
+		},
+		{
+			`let f = fn() { defer record(1); throw "boom"; }; try { f() } catch (e) { record(e) }`,
+			[]string{"1", "boom"},
+			nil,
+		},
+		{
+			"let g = fn() { defer record(2); record(1); }; let f = fn() { defer record(3); g(); }; f()",
+			[]string{"1", "2", "3"},
+			nil,
+		},
+		{
+			`let f = fn() { defer fn() { throw "in defer" }(); 1 }; f()`,
+			[]string{},
+			errorMessage("in defer"),
+		},
+		{
+			"defer record(1);",
+			[]string{},
+			errorMessage("defer outside function"),
+		},
+	}
+
+	for _, tt := range tests {
+		recorded := []string{}
+		env := object.NewEnvironment()
+		env.Set("record", &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				recorded = append(recorded, args[0].Inspect())
+				return NULL
+			},
+		})
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := Eval(program, env)
+
+		testObject(t, tt.input, evaluated, tt.result)
+		if len(recorded) != len(tt.expected) {
+			t.Errorf("wrong deferred calls for %q. got=%v, want=%v", tt.input, recorded, tt.expected)
+			continue
+		}
+		for i, value := range tt.expected {
+			if recorded[i] != value {
+				t.Errorf("wrong deferred calls for %q. got=%v, want=%v", tt.input, recorded, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fn add(x, y) { x + y } add(1, 2)", 3},
+		{"let result = add(1, 2); fn add(x, y) { x + y } result", 3},
+		{
+			`
+let result = isEven(10);
+fn isEven(x) { if (x == 0) { true } else { isOdd(x - 1) } }
+fn isOdd(x) { if (x == 0) { false } else { isEven(x - 1) } }
+result`,
+			true,
+		},
+		{
+			`
+let f = fn(n) {
+  let result = even(n);
+  fn even(x) { if (x == 0) { true } else { odd(x - 1) } }
+  fn odd(x) { if (x == 0) { false } else { even(x - 1) } }
+  result
+};
+f(7)`,
+			false,
+		},
+		{"fn fact(n) { if (n == 0) { 1 } else { n * fact(n - 1) } } fact(5)", 120},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFunctionNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(x, y) { x + y } add", "fn add(x, y) {\n(x + y)\n}"},
+		{"let add = fn(x, y) { x + y }; add", "fn add(x, y) {\n(x + y)\n}"},
+		{"fn(x) { x }", "fn(x) {\nx\n}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		fn, ok := evaluated.(*object.Function)
+		if !ok {
+			t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+		}
+		if fn.Inspect() != tt.expected {
+			t.Errorf("fn.Inspect() wrong. want=%q, got=%q", tt.expected, fn.Inspect())
+		}
+	}
+}
+
+func TestStackTraceFunctionNames(t *testing.T) {
+	input := `
+fn outer() { let alias = inner; alias() }
+fn inner() { fn() { 1 + true }() }
+outer()`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := []string{"<anonymous>", "inner", "outer"}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong stack. want=%v, got=%v", expected, errObj.Stack)
+	}
+	for i, frame := range expected {
+		if errObj.Stack[i] != frame {
+			t.Errorf("wrong stack. want=%v, got=%v", expected, errObj.Stack)
+		}
+	}
+}
+
+func TestBlockScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 1; if (true) { let x = 2; }; x", 1},
+		{"let x = 1; if (true) { let x = 2; x } else { 3 }", 2},
+		{"let x = 1; if (false) { 0 } else { let x = 3; }; x", 1},
+		{"if (true) { let y = 2; }; y", "identifier not found: y"},
+		{"let f = fn() { if (true) { let y = 2; }; y }; f()", "identifier not found: y"},
+		{"let f = fn(x) { if (true) { let x = x + 1; }; x }; f(1)", 1},
+		{"let x = 1; if (true) { let y = x + 1; y }", 2},
+		{"let x = 1; try { let x = 2; } finally { let x = 3; }; x", 1},
+		{"if (true) { fn g() { 1 } }; g()", "identifier not found: g"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestClosuresCreatedInsideBlocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let f = fn() { if (true) { let x = 1; fn() { x } } }; f()()", 1},
+		{
+			`
+let makeCounter = fn(flag) {
+  if (flag) { let start = 10; fn(n) { start + n } } else { let start = 20; fn(n) { start + n } }
+};
+let a = makeCounter(true);
+let b = makeCounter(false);
+a(1) + b(2)`,
+			33,
+		},
+		{
+			`
+let x = 100;
+let f = if (true) { let x = 1; fn() { x } };
+let x = 200;
+f() + x`,
+			201,
+		},
+		{
+			`
+let f = fn() {
+  if (true) {
+    let base = 5;
+    fn helper(n) { if (n == 0) { base } else { helper(n - 1) + 1 } }
+    helper
+  }
+};
+f()(3)`,
+			8,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestNullAndNullSafeOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"null", nil},
+		{"null == null", true},
+		{"null != 1", true},
+		{"null ?? 5", 5},
+		{"1 ?? 5", 1},
+		{"false ?? 5", false},
+		{"null ?? null ?? 3", 3},
+		{"1 ?? undefinedName", 1},
+		{`let h = {"a": {"b": 1}}; h?.a?.b`, 1},
+		{`let h = {"a": {"b": 1}}; h?.x?.b`, nil},
+		{`let h = {"a": {"b": 1}}; h?.x?.b ?? 42`, 42},
+		{`let h = {"a": [1, 2]}; h?["a"]?[1]`, 2},
+		{`let h = {"a": [1, 2]}; h?["b"]?[1]`, nil},
+		{"let a = null; a?[0]", nil},
+		{"let a = null; a?.b", nil},
+		{`let h = {}; h["x"]["y"]`, "index operator not supported: NULL"},
+		{"5?.b", "index operator not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestSpreadExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1, 2]; let b = [3]; [...a, ...b]", "[1, 2, 3]"},
+		{"let a = [1, 2]; [0, ...a, 9]", "[0, 1, 2, 9]"},
+		{"[...[]]", "[]"},
+		{"let add = fn(x, y, z) { x + y + z }; let args = [1, 2, 3]; add(...args)", "6"},
+		{"let add = fn(x, y, z) { x + y + z }; add(1, ...[2, 3])", "6"},
+		{"len(...[[1, 2, 3]])", "3"},
+		{`let defaults = {"a": 1, "b": 2}; let overrides = {"b": 3}; let h = {...defaults, ...overrides}; [h["a"], h["b"]]`, "[1, 3]"},
+		{`let defaults = {"a": 1}; let h = {"a": 2, ...defaults}; h["a"]`, "1"},
+		{`{"x": 1, ...{"x": 2}}["x"]`, "2"},
+		{`{...{"x": 2}, "x": 1}["x"]`, "1"},
+		{`let n = 0; {...{"a": n}, "a": 2, ...{"a": 3}, "b": 4}`, "{a: 3, b: 4}"},
+		{`let d = {"a": 1}; let h = {...d}; [len([h]), h["a"]]`, "[1, 1]"},
+		{"[...1]", "ERROR: spread of INTEGER not supported in a list"},
+		{"{...[1]}", "ERROR: spread of ARRAY not supported in a hash"},
+		{"[...undefinedName]", "ERROR: identifier not found: undefinedName"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestRangeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0..5", "0..5"},
+		{"0..=5", "0..=5"},
+		{"let n = 3; 0..n + 1", "0..4"},
+		{"len(0..5)", "5"},
+		{"len(0..=5)", "6"},
+		{"len(5..0)", "0"},
+		{"len(0..1000000000000)", "1000000000000"},
+		{"(2..10)[0]", "2"},
+		{"(2..10)[7]", "9"},
+		{"(2..10)[8]", "null"},
+		{"(2..=10)[8]", "10"},
+		{"(2..10)[-1]", "null"},
+		{"array(0..5)", "[0, 1, 2, 3, 4]"},
+		{"array(1..=3)", "[1, 2, 3]"},
+		{"array(3..1)", "[]"},
+		{"[...0..3, 10]", "[0, 1, 2, 10]"},
+		{"let add = fn(a, b) { a + b }; add(...1..3)", "3"},
+		{"first(3..7)", "3"},
+		{"last(3..7)", "6"},
+		{"last(3..=7)", "7"},
+		{"rest(3..7)", "4..7"},
+		{"first(3..3)", "null"},
+		{"[10, 20, 30, 40][1..3]", "[20, 30]"},
+		{"[10, 20, 30, 40][1..=3]", "[20, 30, 40]"},
+		{"[10, 20, 30, 40][2..100]", "[30, 40]"},
+		{"[10, 20, 30, 40][3..1]", "[]"},
+		{`"hello"[1..4]`, "ell"},
+		{"(0..100)[10..20]", "10..20"},
+		{"(0..100)[90..=200]", "90..100"},
+		{"len((0..1000000000000)[5..10])", "5"},
+		{"len(0..=9223372036854775807)", "9223372036854775808"},
+		{"len(-9223372036854775807 - 1..=9223372036854775807)", "18446744073709551616"},
+		{"len(-9223372036854775807..9223372036854775807)", "18446744073709551614"},
+		{"len(9223372036854775807..=9223372036854775807)", "1"},
+		{"len(9223372036854775807..-9223372036854775807)", "0"},
+		{"(0..=9223372036854775807)[9223372036854775807]", "9223372036854775807"},
+		{"(-9223372036854775807 - 1..=9223372036854775807)[9223372036854775807]", "-1"},
+		{"(5..=9223372036854775807)[9223372036854775802]", "9223372036854775807"},
+		{"(5..=9223372036854775807)[9223372036854775803]", "null"},
+		{"[first(0..=9223372036854775807), last(0..=9223372036854775807)]", "[0, 9223372036854775807]"},
+		{"rest(9223372036854775806..=9223372036854775807)", "9223372036854775807..=9223372036854775807"},
+		{"rest(9223372036854775807..=9223372036854775807)", "9223372036854775807..9223372036854775807"},
+		{"len(rest(-9223372036854775807 - 1..=9223372036854775807))", "18446744073709551615"},
+		{"(5..=9223372036854775807)[9223372036854775800..=9223372036854775807]", "9223372036854775805..=9223372036854775807"},
+		{"(0..=9223372036854775807)[9223372036854775806..=9223372036854775807]", "9223372036854775806..=9223372036854775807"},
+		{"(0..=9223372036854775807)[9223372036854775807..9223372036854775807]", "9223372036854775807..9223372036854775807"},
+		{"(-9223372036854775807 - 1..=9223372036854775807)[9223372036854775806..=9223372036854775807]", "-2..0"},
+		{"array(9223372036854775806..=9223372036854775807)", "[9223372036854775806, 9223372036854775807]"},
+		{"0..=9223372036854775807 == 0..=9223372036854775807", "true"},
+		{"array(-9223372036854775807..9223372036854775807)", "ERROR: range too long to materialise: 18446744073709551614 elements, the limit is 67108864"},
+		{"array(0..100000000)", "ERROR: range too long to materialise: 100000000 elements, the limit is 67108864"},
+		{"[...0..=9223372036854775807]", "ERROR: range too long to materialise: 9223372036854775808 elements, the limit is 67108864"},
+		{"1..true", "ERROR: type mismatch: INTEGER .. BOOLEAN"},
+		{"5[0..1]", "ERROR: slice operator not supported: INTEGER"},
+		{"array(1)", "ERROR: argument to `array` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn gen() { yield 1; yield 2; yield 3; } array(gen())", "[1, 2, 3]"},
+		{"fn gen() { yield 1; } gen()", "generator gen"},
+		{"let g = fn() { yield 1; }(); g", "generator"},
+		{"fn gen(n) { yield n; yield n * 2; } let g = gen(5); [next(g), next(g), next(g, 0)]", "[5, 10, 0]"},
+		{"fn gen() { yield 1; return 5; yield 2; } array(gen())", "[1]"},
+		{"fn gen() { if (true) { yield 1; }; yield 2; } [...gen()]", "[1, 2]"},
+		{"fn gen() { yield ...[1, 2]; yield ...3..5; yield 9; } array(gen())", "[1, 2, 3, 4, 9]"},
+		{"fn gen() { yield 1; 1 + true; yield 2; } let g = gen(); next(g)", "1"},
+		{"fn gen() { yield 1; 1 + true; yield 2; } array(gen())", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"fn gen() { yield 1; } let g = gen(); next(g); next(g)", "ERROR: iterator is exhausted"},
+		{"fn gen() { yield 1; } let g = gen(); array(g); array(g)", "[]"},
+		{"yield 1;", "ERROR: yield outside generator"},
+		{"fn gen() { let f = fn() { 1 }; yield f(); } array(gen())", "[1]"},
+		{"fn gen() { yield ...5; } array(gen())", "ERROR: argument to `yield` not supported, got INTEGER"},
+		{
+			"fn gen() { try { yield 1; throw 2; } catch (e) { yield e + 10; } } array(gen())",
+			"[1, 12]",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestInfiniteGenerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"fn naturals(n) { yield n; yield ...naturals(n + 1); } array(take(naturals(0), 5))",
+			"[0, 1, 2, 3, 4]",
+		},
+		{
+			"fn fib(a, b) { yield a; yield ...fib(b, a + b); } array(take(fib(0, 1), 10))",
+			"[0, 1, 1, 2, 3, 5, 8, 13, 21, 34]",
+		},
+		{
+			`
+fn naturals(n) { yield n; yield ...naturals(n + 1); }
+let evens = filter(naturals(0), fn(x) { x / 2 * 2 == x });
+array(take(map(evens, fn(x) { x * x }), 4))`,
+			"[0, 4, 16, 36]",
+		},
+		{
+			"fn naturals(n) { yield n; yield ...naturals(n + 1); } let g = naturals(10); next(g); next(g)",
+			"11",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestIterationBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"array(iter([1, 2, 3]))", "[1, 2, 3]"},
+		{`array("abc")`, "[a, b, c]"},
+		{`array({"a": 1})`, "[[a, 1]]"},
+		{"array(iter(1..4))", "[1, 2, 3]"},
+		{"let it = iter([1, 2]); [next(it), next(it), next(it, null)]", "[1, 2, null]"},
+		{"next([1])", "ERROR: argument to `next` not supported, got ARRAY"},
+		{"iter(1)", "ERROR: argument to `iter` not supported, got INTEGER"},
+		{"map([1], fn(x) { x })", "iterator"},
+		{"array(map([1, 2, 3], fn(x) { x * 10 }))", "[10, 20, 30]"},
+		{"array(filter(0..10, fn(x) { x > 6 }))", "[7, 8, 9]"},
+		{"array(take(0..1000000000000, 3))", "[0, 1, 2]"},
+		{"array(take([1], 3))", "[1]"},
+		{"reduce(1..=10, 0, fn(acc, x) { acc + x })", "55"},
+		{`reduce("abc", "", fn(acc, x) { x + acc })`, "cba"},
+		{"each([1, 2], fn(x) { x })", "null"},
+		{"each([1, true], fn(x) { x + 1 })", "ERROR: type mismatch: BOOLEAN + INTEGER"},
+		{"array(map([1, true], fn(x) { -x }))", "ERROR: unknown operator: -BOOLEAN"},
+		{"[...map(1..3, fn(x) { x + 1 })]", "[2, 3]"},
+		{"let it = iter(0..3); next(it); array(it)", "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestIterationIsLazy(t *testing.T) {
+	input := `
+fn naturals(n) { record(n); yield n; yield ...naturals(n + 1); }
+let squares = map(naturals(0), fn(x) { x * x });
+array(take(squares, 3))`
+
+	recorded := []string{}
+	env := object.NewEnvironment()
+	env.Set("record", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			recorded = append(recorded, args[0].Inspect())
+			return NULL
+		},
+	})
+	evaluated := Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+
+	if evaluated.Inspect() != "[0, 1, 4]" {
+		t.Errorf("wrong result. got=%q", evaluated.Inspect())
+	}
+	if len(recorded) != 3 {
+		t.Errorf("generator was resumed too often. got=%v", recorded)
+	}
+}
+
+func TestGeneratorDefer(t *testing.T) {
+	input := `
+fn gen() { defer record("done"); yield 1; yield 2; }
+let g = gen();
+record(next(g));
+record(next(g));
+next(g, null);
+0`
+
+	recorded := []string{}
+	env := object.NewEnvironment()
+	env.Set("record", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			recorded = append(recorded, args[0].Inspect())
+			return NULL
+		},
+	})
+	Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+
+	expected := []string{"1", "2", "done"}
+	if len(recorded) != len(expected) {
+		t.Fatalf("wrong calls. want=%v, got=%v", expected, recorded)
+	}
+	for i, value := range expected {
+		if recorded[i] != value {
+			t.Errorf("wrong calls. want=%v, got=%v", expected, recorded)
+		}
+	}
+}
+
+func TestGeneratorClose(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			`
+fn gen() { defer record("deferred"); try { yield 1; yield 2; } finally { record("finally"); } }
+let g = gen();
+record(next(g));
+record(close(g));
+record(next(g, "exhausted"));
+record(close(g));`,
+			[]string{"1", "finally", "deferred", "null", "exhausted", "null"},
+		},
+		{
+			`
+fn naturals(n) { defer record(n); yield n; yield ...naturals(n + 1); }
+let g = naturals(0);
+next(g);
+next(g);
+next(g);
+close(map(g, fn(x) { x }));`,
+			[]string{"2", "1", "0"},
+		},
+		{
+			`
+fn gen() { defer record("deferred"); yield 1; }
+close(gen());`,
+			[]string{},
+		},
+		{
+			`
+fn gen() { try { yield 1; yield 2; } finally { record("finally"); } }
+record(array(take(gen(), 1)));`,
+			[]string{"finally", "[1]"},
+		},
+		{
+			`
+fn gen() { defer record("deferred"); yield 1; yield 2; }
+record(reduce(take(gen(), 1), 0, fn(acc, x) { acc + x }));`,
+			[]string{"deferred", "1"},
+		},
+		{
+			`
+fn gen() { defer record("deferred"); yield 1; yield 2; }
+array(map(gen(), fn(x) { x + true }));`,
+			[]string{"deferred"},
+		},
+		{
+			`
+fn gen() { defer record("deferred"); yield 1; yield 2; }
+each(gen(), fn(x) { throw x });`,
+			[]string{"deferred"},
+		},
+		{
+			`
+fn gen() { yield 1; }
+fn consume() { let g = gen(); defer close(g); next(g) }
+record(consume());`,
+			[]string{"1"},
+		},
+		{
+			`
+fn gen() { defer fn() { throw "in defer" }(); yield 1; }
+let g = gen();
+next(g);
+record(try { close(g) } catch (e) { e });`,
+			[]string{"in defer"},
+		},
+	}
+
+	for _, tt := range tests {
+		recorded := []string{}
+		env := object.NewEnvironment()
+		env.Set("record", &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				recorded = append(recorded, args[0].Inspect())
+				return NULL
+			},
+		})
+		Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+
+		if len(recorded) != len(tt.expected) {
+			t.Errorf("wrong calls for %q. want=%v, got=%v", tt.input, tt.expected, recorded)
+			continue
+		}
+		for i, value := range tt.expected {
+			if recorded[i] != value {
+				t.Errorf("wrong calls for %q. want=%v, got=%v", tt.input, tt.expected, recorded)
+			}
+		}
+	}
+	if result := testEval("close([1])"); result.Inspect() != "ERROR: argument to `close` not supported, got ARRAY" {
+		t.Errorf("wrong result for close([1]). got=%q", result.Inspect())
+	}
+}
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x, y } Point", "struct Point { x, y }"},
+		{"struct Point { x, y } Point(1, 2)", "Point{x: 1, y: 2}"},
+		{`struct User { name, tags } User("ann", [1, 2])`, "User{name: ann, tags: [1, 2]}"},
+		{"struct Unit {} Unit()", "Unit{}"},
+		{"struct Point { x, y } let p = Point(1, 2); p.x + p.y", "3"},
+		{`struct Point { x, y } Point(1, 2)["y"]`, "2"},
+		{"struct Point { x, y } Point(1, 2).z", "ERROR: unknown field `z` of Point"},
+		{"struct Point { x, y } Point(1, 2)[0]", "ERROR: field name must be STRING, got INTEGER"},
+		{"struct Point { x, y } Point(1)", "ERROR: wrong number of arguments to `Point`, got=1, want=2"},
+		{"struct Point { x, y } let p = Point(1, 2); let q = p with { x: 3 }; [p, q]", "[Point{x: 1, y: 2}, Point{x: 3, y: 2}]"},
+		{"struct Point { x, y } Point(1, 2) with { y: 5, x: 4 }", "Point{x: 4, y: 5}"},
+		{"struct Point { x, y } Point(1, 2) with { z: 3 }", "ERROR: unknown field `z` of Point"},
+		{"{} with { x: 1 }", "ERROR: with operator not supported: HASH"},
+		{"struct Line { from, to } struct Point { x, y } Line(Point(0, 0), Point(1, 1)).to.y", "1"},
+		{"struct Point { x, y } let p = null; p?.x ?? 0", "0"},
+		{`let h = {"x": 1}; [h.x, h.y]`, "[1, null]"},
+		{"struct Point { x, y } Point(1, 2) + Point(1, 2)", "ERROR: unknown operator: RECORD + RECORD"},
+		{"struct Point { x, y } let p = Point(1, 2); fn f() { p.z } f()", "ERROR: unknown field `z` of Point"},
+		{"if (true) { struct Point { x } } Point", "ERROR: identifier not found: Point"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestRecordEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"struct Point { x, y } Point(1, 2) == Point(1, 2)", true},
+		{"struct Point { x, y } Point(1, 2) != Point(1, 2)", false},
+		{"struct Point { x, y } Point(1, 2) == Point(2, 1)", false},
+		{`struct Name { first } Name("a") == Name("a")`, true},
+		{"struct Point { x, y } struct Pair { x, y } Point(1, 2) == Pair(1, 2)", false},
+		{"struct Line { from, to } struct Point { x, y } Line(Point(0, 0), Point(1, 1)) == Line(Point(0, 0), Point(1, 1))", true},
+		{"struct Point { x, y } let p = Point(1, 2); p with { x: 1 } == p", true},
+		{"struct Point { x, y } Point(1, 2) == 1", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestRecordsAsHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`struct Point { x, y } let h = {Point(1, 2): "a"}; h[Point(1, 2)]`, "a"},
+		{`struct Point { x, y } let h = {Point(1, 2): "a"}; h[Point(2, 1)]`, "null"},
+		{`struct Point { x, y } struct Pair { x, y } let h = {Point(1, 2): "a"}; h[Pair(1, 2)]`, "null"},
+		{`struct Line { from, to } struct Point { x, y } let h = {Line(Point(0, 0), Point(1, 1)): 1}; h[Line(Point(0, 0), Point(1, 1))]`, "1"},
+		{`struct Point { x, y } {Point({}, 2): "a"}`, "ERROR: unusable as hash key: RECORD"},
+		{`struct Point { x, y } let h = {}; h[Point({}, 2)]`, "ERROR: unusable as hash key: RECORD"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"c": 1, "a": 2, "b": 3}`, "{c: 1, a: 2, b: 3}"},
+		{`{3: "c", 1: "a", 2: "b"}`, "{3: c, 1: a, 2: b}"},
+		{`let it = iter(1..5); {next(it): next(it), next(it): next(it)}`, "{1: 2, 3: 4}"},
+		{`{"a": 1, "b": 2, "a": 3}`, "{a: 3, b: 2}"},
+		{`let h = {"z": 1, "y": 2}; {...h, "x": 3, "z": 4}`, "{z: 4, y: 2, x: 3}"},
+		{`{"a": 1, ...{"b": 2}}`, "{a: 1, b: 2}"},
+		{`{"a": 1, ...{"b": 2, "a": 3}, "c": 4}`, "{a: 3, b: 2, c: 4}"},
+		{`let it = iter(1..4); {"a": next(it), ...{"b": next(it)}, "c": next(it)}`, "{a: 1, b: 2, c: 3}"},
+		{`array({"b": 1, "a": 2})`, "[[b, 1], [a, 2]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestCompositeHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let grid = {[0, 0]: "origin", [1, 2]: "a"}; [grid[[1, 2]], grid[[2, 1]]]`, "[a, null]"},
+		{`let h = {[1, [2, 3]]: "nested"}; h[[1, [2, 3]]]`, "nested"},
+		{`let h = {[1, 2]: "array", (1, 2): "tuple"}; [h[[1, 2]], h[(1, 2)]]`, "[array, tuple]"},
+		{`let h = {null: "none"}; h[null]`, "none"},
+		{`let h = {...{[1]: "a"}, [1]: "b"}; [len(array(h)), h[[1]]]`, "[1, b]"},
+		{`let h = {[1]: "a", ...{[1]: "b"}}; [len(array(h)), h[[1]]]`, "[1, b]"},
+		{"#{[1, 2], [1, 2], [2, 1]}", "#{[1, 2], [2, 1]}"},
+		{"#{[1, 2]}[[1, 2]]", "true"},
+		{"{[1, fn(x) { x }]: 1}", "ERROR: unusable as hash key: ARRAY"},
+		{"{[1, {}]: 1}", "ERROR: unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestPersistentCollections(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1, 2]; let b = push(a, 3); [a, b]", "[[1, 2], [1, 2, 3]]"},
+		{"let a = [1, 2, 3]; let b = assoc(a, 1, 20); [a, b]", "[[1, 2, 3], [1, 20, 3]]"},
+		{"reduce(0..100, [], fn(acc, x) { push(acc, x) })[99]", "99"},
+		{`let h = {"a": 1}; let g = assoc(h, "b", 2); [h, g, assoc(g, "a", 3)]`, "[{a: 1}, {a: 1, b: 2}, {a: 3, b: 2}]"},
+		{`len(array(reduce(0..100, {}, fn(acc, x) { assoc(acc, x, x * x) })))`, "100"},
+		{"assoc([1], 1, 2)", "ERROR: index out of range: 1"},
+		{`assoc([1], "a", 2)`, "ERROR: index to `assoc` must be INTEGER, got STRING"},
+		{"assoc({}, fn(x) { x }, 1)", "ERROR: unusable as hash key: FUNCTION"},
+		{"assoc(1, 2, 3)", "ERROR: argument to `assoc` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	enum := "enum State { Pending, Done(result), Failed(code, reason) } "
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{enum + "State", "enum State { Pending, Done(result), Failed(code, reason) }"},
+		{enum + "State.Pending", "State.Pending"},
+		{enum + "State.Done", "variant State.Done(result)"},
+		{enum + "State.Done(5)", "State.Done(5)"},
+		{enum + `State.Failed(2, "oops")`, "State.Failed(2, oops)"},
+		{enum + "State.Done(5).result", "5"},
+		{enum + `State.Failed(2, "oops")["reason"]`, "oops"},
+		{enum + "State.Done(5).code", "ERROR: unknown field `code` of State.Done"},
+		{enum + "State.Running", "ERROR: unknown variant `Running` of State"},
+		{enum + "State.Done()", "ERROR: wrong number of arguments to `State.Done`, got=0, want=1"},
+		{enum + "State.Pending()", "ERROR: not a function: ENUM_VALUE"},
+		{enum + "[is(State.Done(1), State.Done), is(State.Done(1), State.Failed), is(State.Pending, State.Pending)]", "[true, false, true]"},
+		{enum + "is(1, State.Done)", "false"},
+		{enum + "is(State.Pending, 1)", "ERROR: argument to `is` must be VARIANT, got INTEGER"},
+		{
+			enum + `fn describe(s) { if (is(s, State.Done)) { s.result } else { "pending" } } [describe(State.Done(1)), describe(State.Pending)]`,
+			"[1, pending]",
+		},
+		{enum + `let h = {State.Pending: 1, State.Done(2): 3}; [h[State.Pending], h[State.Done(2)], h[State.Done(3)]]`, "[1, 3, null]"},
+		{enum + "{State.Done({}): 1}", "ERROR: unusable as hash key: ENUM_VALUE"},
+		{enum + "State.Pending + State.Pending", "ERROR: unknown operator: ENUM_VALUE + ENUM_VALUE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestEnumEquality(t *testing.T) {
+	enum := "enum State { Pending, Done(result) } enum Other { Pending } "
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{enum + "State.Pending == State.Pending", true},
+		{enum + "State.Pending != State.Pending", false},
+		{enum + "State.Done(1) == State.Done(1)", true},
+		{enum + `State.Done("a") == State.Done("a")`, true},
+		{enum + "State.Done(1) == State.Done(2)", false},
+		{enum + "State.Done(1) == State.Pending", false},
+		{enum + "State.Pending == Other.Pending", false},
+		{enum + "State.Done(State.Pending) == State.Done(State.Pending)", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestStructuralEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{`"a" == "b"`, false},
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{`[1, "a", [true, null]] == [1, "a", [true, null]]`, true},
+		{"[] == []", true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{`{"a": {"b": [1]}} == {"a": {"b": [1]}}`, true},
+		{"{} == {}", true},
+		{"null == null", true},
+		{"[null] == [null]", true},
+		{"[1] == 1", false},
+		{`1 == "1"`, false},
+		{"0..3 == 0..=2", true},
+		{"0..0 == 5..1", true},
+		{"0..3 == [0, 1, 2]", false},
+		{"let f = fn(x) { x }; f == f", true},
+		{"fn(x) { x } == fn(x) { x }", false},
+		{`struct User { name, tags } User("a", [1]) == User("a", [1])`, true},
+		{`enum Result { Ok(value) } Result.Ok({"a": [1]}) == Result.Ok({"a": [1]})`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"abc" > "ab"`, true},
+		{`"ab" <= "ab"`, true},
+		{`"B" < "a"`, true},
+		{`"" >= ""`, true},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2]", false},
+		{"[1, 2] <= [1, 2]", true},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"[2] > [1, 9]", true},
+		{"[] < [1]", true},
+		{`[["a"], 1] < [["b"], 0]`, true},
+		{`[1] < ["a"]`, "unordered operands: ARRAY < ARRAY (element 0: INTEGER < STRING)"},
+		{"[true] < [false]", "unordered operands: ARRAY < ARRAY (element 0: BOOLEAN < BOOLEAN)"},
+		{`[1, [2, "a"]] >= [1, [2, 3]]`, "unordered operands: ARRAY >= ARRAY (element 1: ARRAY >= ARRAY (element 1: STRING >= INTEGER))"},
+		{`(1, {}) < (1, {})`, "unordered operands: TUPLE < TUPLE (element 1: HASH < HASH)"},
+		{"[1, 2] - [1]", "unknown operator: ARRAY - ARRAY"},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+		{`"a" < 1`, "type mismatch: STRING < INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"9223372036854775807 + 1 - 1", 9223372036854775807},
+		{"123456789012345678901234567890 / 123456789012345678901234567890", 1},
+		{"-(9223372036854775807 + 1)", -9223372036854775807 - 1},
+		{"-123456789012345678901234567890 / 7", "-17636684144620811271604938270"},
+		{"9223372036854775807 + 1 > 9223372036854775807", true},
+		{"9223372036854775808 == 9223372036854775807 + 1", true},
+		{"9223372036854775808 != 1", true},
+		{"[9223372036854775808] < [9223372036854775809]", true},
+		{`let h = {9223372036854775808: "big"}; h[9223372036854775807 + 1]`, "big"},
+		{"9223372036854775808 / 0", "division by zero"},
+		{`9223372036854775808 + "a"`, "type mismatch: BIGINT + STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.BigInt:
+				if obj.Value.String() != expected {
+					t.Errorf("wrong value for %q. want=%s, got=%s", tt.input, expected, obj.Value)
+				}
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong value for %q. want=%q, got=%q", tt.input, expected, obj.Value)
+				}
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, expected, obj.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestTrapOnOverflow(t *testing.T) {
+	OverflowPolicy = TrapOnOverflow
+	defer func() { OverflowPolicy = PromoteOnOverflow }()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "integer overflow: -9223372036854775807 - 2"},
+		{"4294967296 * 4294967296", "integer overflow: 4294967296 * 4294967296"},
+		{"-(-9223372036854775807 - 1)", "integer overflow: -(-9223372036854775808)"},
+		{"9223372036854775808", "integer overflow: 9223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", tt.input)
+			continue
+		}
+		if errObj.Kind != object.OVERFLOW_ERROR {
+			t.Errorf("wrong error kind for %q. want=%s, got=%s", tt.input, object.OVERFLOW_ERROR, errObj.Kind)
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+	testIntegerObject(t, testEval("9223372036854775806 + 1"), 9223372036854775807)
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`b"ab\x00\xff"`, `b"ab\x00\xff"`},
+		{`b"a\"b\\"`, `b"a\"b\\"`},
+		{`len(b"\x00\x01\x02")`, "3"},
+		{`b"abc"[1]`, "98"},
+		{`b"abc"[3]`, "null"},
+		{`b"abcd"[1..3]`, `b"bc"`},
+		{`[first(b"ab"), last(b"ab"), rest(b"ab")]`, `[97, 98, b"b"]`},
+		{`b"ab" + b"\n"`, `b"ab\n"`},
+		{`array(b"ab")`, "[97, 98]"},
+		{`bytes([104, 105])`, `b"hi"`},
+		{"bytes(3)", `b"\x00\x00\x00"`},
+		{`[b"a" == b"a", b"a" != b"b", b"a" < b"ab", b"b" > b"ab", b"a" == "a"]`, "[true, true, true, true, false]"},
+		{`let h = {b"k": 1}; h[b"k"]`, "1"},
+		{`encode(decode(b"h\xc3\xa9llo", "utf-8"), "utf-8")`, `b"h\xc3\xa9llo"`},
+		{`encode(decode(b"h\xc3\xa9llo", "utf-8"), "latin-1")`, `b"h\xe9llo"`},
+		{`encode(decode(b"h\xe9", "latin-1"), "utf-16le")`, `b"h\x00\xe9\x00"`},
+		{`encode(decode(b"h\xe9", "latin-1"), "utf-16be")`, `b"\x00h\x00\xe9"`},
+		{`decode(b"h\xc3\xa9", "utf-8")`, "hé"},
+		{`decode(b"h\xe9", "latin-1")`, "hé"},
+		{`decode(encode(decode(b"a\xf0\x9f\x98\x80", "utf-8"), "utf-16le"), "utf-16le")`, "a😀"},
+		{`hex(b"\x01\xab")`, "01ab"},
+		{`unhex("01ab")`, `b"\x01\xab"`},
+		{`base64(b"hello")`, "aGVsbG8="},
+		{`unbase64("aGVsbG8=")`, `b"hello"`},
+		{`encode(decode(b"\xe9", "latin-1"), "ascii")`, `ERROR: cannot encode 'é' in ascii`},
+		{`decode(b"\xff", "utf-8")`, "ERROR: invalid utf-8 at byte 0"},
+		{`decode(b"a\x80", "ascii")`, "ERROR: invalid ascii at byte 1"},
+		{`decode(b"a", "utf-16le")`, "ERROR: odd number of bytes for utf-16: 1"},
+		{`decode(b"a\x00\x00\xd8", "utf-16le")`, "ERROR: invalid utf-16 at byte 2"},
+		{`decode(b"\x00\xdca\x00", "utf-16le")`, "ERROR: invalid utf-16 at byte 0"},
+		{`decode(b"\xd8\x00\x00a", "utf-16be")`, "ERROR: invalid utf-16 at byte 0"},
+		{`decode(b"\xd8\x3d\xde\x00", "utf-16be")`, "😀"},
+		{`encode("a", "ebcdic")`, "ERROR: unknown encoding: ebcdic"},
+		{`unhex("0g")`, "ERROR: invalid hex: encoding/hex: invalid byte: U+0067 'g'"},
+		{`unbase64("!")`, "ERROR: invalid base64: illegal base64 data at input byte 0"},
+		{"bytes([256])", "ERROR: byte out of range: 256"},
+		{"bytes(10000000000000)", "ERROR: size must be at most 1073741824, got 10000000000000"},
+		{`bytes("a")`, "ERROR: argument to `bytes` not supported, got STRING, use `encode`"},
+		{`b"a" + "a"`, "ERROR: type mismatch: BYTES + STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	Clock = func() time.Time { return time.Date(2024, time.February, 29, 12, 30, 0, 0, time.UTC) }
+	defer func() { Clock = time.Now }()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"now()", "2024-02-29T12:30:00Z"},
+		{`now() + duration("36h")`, "2024-03-02T00:30:00Z"},
+		{`now() - duration("1m30s")`, "2024-02-29T12:28:30Z"},
+		{`duration("1h") + now()`, "2024-02-29T13:30:00Z"},
+		{`now() - time("2024-01-01", "date")`, "1428h30m0s"},
+		{`time("2024-02-29T21:30:00+09:00") == now()`, "true"},
+		{`[time("2024-01-01", "date") < now(), now() <= now(), now() > now()]`, "[true, true, false]"},
+		{`let h = {now(): 1}; h[time("2024-02-29T21:30:00+09:00")]`, "1"},
+		{"time(0)", "1970-01-01T00:00:00Z"},
+		{`time("2024-02-29 12:30:00", "datetime", "Asia/Tokyo")`, "2024-02-29T12:30:00+09:00"},
+		{`time("29/02/2024", "02/01/2006")`, "2024-02-29T00:00:00Z"},
+		{`timezone(now(), "Asia/Tokyo")`, "2024-02-29T21:30:00+09:00"},
+		{`timezone(now(), "America/New_York").hour`, "7"},
+		{`format(now(), "date")`, "2024-02-29"},
+		{`format(now(), "kitchen")`, "12:30PM"},
+		{`format(timezone(now(), "Asia/Tokyo"), "Mon, 02 Jan 2006 15:04 MST")`, "Thu, 29 Feb 2024 21:30 JST"},
+		{"let t = now(); [t.year, t.month, t.day, t.hour, t.minute, t.second, t.yearday]", "[2024, 2, 29, 12, 30, 0, 60]"},
+		{"[now().weekday, now().zone, now().offset, now().unix]", "[Thursday, UTC, 0, 1709209800]"},
+		{`duration("1h30m")`, "1h30m0s"},
+		{`duration("1h") * 3 - duration("10m")`, "2h50m0s"},
+		{`2 * duration("1m") / 4`, "30s"},
+		{`duration("1h") / duration("7m")`, "8"},
+		{`-duration("1s")`, "-1s"},
+		{`let d = duration("1h30m"); [d.hours, d.minutes, d.seconds, d.milliseconds]`, "[1, 90, 5400, 5400000]"},
+		{`[duration("60s") == duration("1m"), duration("1s") < duration("1m"), duration("1s") == 1]`, "[true, true, false]"},
+		{`duration("2562047h") * 2`, "ERROR: duration overflow: 2562047h0m0s * 2"},
+		{`duration("1h") / 0`, "ERROR: division by zero"},
+		{`time("2024-02-30", "date")`, `ERROR: could not parse "2024-02-30" as time: parsing time "2024-02-30": day out of range`},
+		{`duration("1 hour")`, `ERROR: could not parse "1 hour" as duration`},
+		{`timezone(now(), "Mars/Olympus")`, "ERROR: unknown time zone: Mars/Olympus"},
+		{"now().century", "ERROR: unknown field `century` of TIME"},
+		{"now() + now()", "ERROR: unknown operator: TIME + TIME"},
+		{"now() + 1", "ERROR: type mismatch: TIME + INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`r"\d+"`, `r"\d+"`},
+		{`r"say \"(\w+)\""`, `r"say \"(\w+)\""`},
+		{`[r"\d+" == regex("\d+"), r"a" == r"b"]`, "[true, false]"},
+		{`let h = {r"a": 1}; h[regex("a")]`, "1"},
+		{`[match(r"^\d+$", "123"), match(r"^\d+$", "12a"), match("b", "abc")]`, "[true, false, true]"},
+		{`find(r"\d+", "ab 12 cd 345")`, "12"},
+		{`find(r"\d+", "abc")`, "null"},
+		{`findall(r"\d+", "ab 12 cd 345")`, "[12, 345]"},
+		{`findall(r"\d+", "abc")`, "[]"},
+		{`captures(r"(\w+)@(?P<host>\w+)\.com", "mail bob@example.com now")`, "{0: bob@example.com, 1: bob, 2: example, host: example}"},
+		{`captures(r"(a)|(b)", "b")`, "{0: b, 1: null, 2: b}"},
+		{`captures(r"\d", "abc")`, "null"},
+		{`let c = captures(r"(?P<y>\d{4})-(?P<m>\d\d)", "on 2024-02-29"); c["y"] + "/" + c["m"]`, "2024/02"},
+		{`replace(r"(\w+)@(\w+)", "bob@example", "$2 at ${1}")`, "example at bob"},
+		{`replace(r"\d+", "a1b22c333", fn(c) { "<" + c[0] + ">" })`, "a<1>b<22>c<333>"},
+		{`replace(r"(?P<n>\d)", "x1y2", fn(c) { first(array(c["n"] + c["n"])) + "!" })`, "x1!y2!"},
+		{`replace(r"\d", "a1", fn(c) { 1 })`, "ERROR: replacement of `replace` must return STRING, got INTEGER"},
+		{`replace(r"\d", "a1", fn(c) { throw "no" })`, "ERROR: no"},
+		{`split(r"\s*,\s*", "a , b,c ,  d")`, "[a, b, c, d]"},
+		{`let parts = split(r",", ""); [len(parts), parts[0] == ""]`, "[1, true]"},
+		{`let parts = split(r",", ",a,"); [len(parts), parts[0] == "", parts[1], parts[2] == ""]`, "[3, true, a, true]"},
+		{`split(r"é", "aébéc")`, "[a, b, c]"},
+		{`regex("(a")`, "ERROR: invalid regex: error parsing regexp: missing closing ): `(a`"},
+		{`match(1, "a")`, "ERROR: regex of `match` must be REGEX or STRING, got INTEGER"},
+		{`match(r"a", 1)`, "ERROR: argument to `match` not supported, got INTEGER"},
+		{`replace(r"a", "a", 1)`, "ERROR: replacement of `replace` must be STRING or FUNCTION, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_parse("null")`, "null"},
+		{`json_parse(" [true, false, 1, -0, 12.50, 1e3, 2.5E-2, 123456789012345678901234567890] ")`, "[true, false, 1, 0, 12.50, 1000, 0.025, 123456789012345678901234567890]"},
+		{`json_parse("1e3") == decimal("1000")`, "true"},
+		{`json_parse(decode(b"{\"b\": [1, {}], \"a\": \"x\", \"b\": []}", "utf-8"))`, "{b: [], a: x}"},
+		{`json_parse(decode(b"\"a\\n\\u00e9\\ud83d\\ude00\\/\"", "utf-8"))`, "a\né😀/"},
+		{`json_parse(decode(b"\"\\ud83d!\"", "utf-8"))`, "\ufffd!"},
+		{`json_stringify(null)`, "null"},
+		{`json_stringify({"b": [1, 2.50d, true, null], "a": "x"})`, `{"b":[1,2.50,true,null],"a":"x"}`},
+		{`json_stringify({"b": 1, "a": (2, 3)}, {"sort_keys": true})`, `{"a":[2,3],"b":1}`},
+		{`json_stringify({"a": [1, {}], "b": []}, {"indent": 2})`, "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": []\n}"},
+		{`json_stringify([1], {"indent": decode(b"\t", "ascii")})`, "[\n\t1\n]"},
+		{`struct Point { x, y }; json_stringify(Point(1, 2))`, `{"x":1,"y":2}`},
+		{`json_stringify(decode(b"\x01\t\"\\", "ascii"))`, `"\u0001\t\"\\"`},
+		{`let v = {"a": [1, {"b": "é"}]}; json_parse(json_stringify(v)) == v`, "true"},
+		{`json_stringify({"a": [1, fn(x) { x }]})`, "ERROR: cannot write FUNCTION as json at $.a[1]"},
+		{`json_stringify({"first name": {1: 2}})`, `ERROR: cannot write INTEGER as json key at $["first name"]`},
+		{`json_stringify(#{1})`, "ERROR: cannot write SET as json at $"},
+		{`json_stringify(1, {"pretty": true})`, "ERROR: unknown option of `json_stringify`: pretty"},
+		{`json_parse("[1, 2")`, "ERROR: invalid json at line 1, column 6 (offset 5): unexpected end of input"},
+		{`json_parse(decode(b"{\"a\": 1,\n \"b\": tru}", "utf-8"))`, "ERROR: invalid json at line 2, column 7 (offset 15): unexpected character 't'"},
+		{`json_parse("[1] 2")`, "ERROR: invalid json at line 1, column 5 (offset 4): unexpected character '2'"},
+		{`json_parse(decode(b"\"\xc3\xa9\\x\"", "utf-8"))`, `ERROR: invalid json at line 1, column 3 (offset 3): invalid escape "\\x"`},
+		{`json_parse("01")`, "ERROR: invalid json at line 1, column 2 (offset 1): unexpected character '1'"},
+		{`json_parse("1e99999")`, "ERROR: invalid json at line 1, column 1 (offset 0): exponent out of range"},
+		{`json_parse("")`, "ERROR: invalid json at line 1, column 1 (offset 0): unexpected end of input"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`csv_parse(decode(b"a,b,c\n1,,3\n", "utf-8"))`, "[[a, b, c], [1, , 3]]"},
+		{`csv_parse(decode(b"a,b\r\n\r\n1,2\r3", "utf-8"))`, "[[a, b], [1, 2], [3]]"},
+		{`csv_parse("")`, "[]"},
+		{`let rows = csv_parse(decode(b"\"x, y\",\"say \"\"hi\"\"\",\"two\nlines\"", "utf-8")); [len(rows), len(rows[0]), rows[0][1]]`, `[1, 3, say "hi"]`},
+		{`csv_parse(decode(b"name;age\nann;31\nbob;27", "utf-8"), {"delimiter": ";", "header": true})`, "[{name: ann, age: 31}, {name: bob, age: 27}]"},
+		{`csv_parse("'a|b'|c", {"delimiter": "|", "quote": "'"})`, "[[a|b, c]]"},
+		{`csv_parse(decode(b"\"a\",b", "utf-8"), {"quote": ""})`, `[["a", b]]`},
+		{`csv_format([["a", 1, 2.50d, true, null], ("x, y", "two words")])`, "a,1,2.50,true,\n\"x, y\",two words\n"},
+		{`csv_format([[decode(b"say \"hi\"", "utf-8")]]) == decode(b"\"say \"\"hi\"\"\"\n", "utf-8")`, "true"},
+		{`csv_format([{"name": "ann", "age": 31}, {"age": 27, "name": "bob"}, {"name": "cid"}])`, "name,age\nann,31\nbob,27\ncid,\n"},
+		{`csv_format([{"a": 1}], {"header": false, "quoting": "all", "delimiter": ";"})`, "\"1\"\n"},
+		{`csv_format(map(1..=3, fn(i) { [i, i * i] }), {"delimiter": "|", "quote": "'"})`, "1|1\n2|4\n3|9\n"},
+		{`let rows = [{"a": "1", "b": "x,y"}]; csv_parse(csv_format(rows), {"header": true}) == rows`, "true"},
+		{`let rows = csv_parse(decode(b"a\x00b,c\nd,e", "utf-8")); [len(rows), len(rows[0]), encode(rows[0][0], "utf-8")]`, `[2, 2, b"a\x00b"]`},
+		{`let rows = csv_parse(decode(b"\"a\x00b\",c", "utf-8")); [len(rows[0]), encode(rows[0][0], "utf-8")]`, `[2, b"a\x00b"]`},
+		{`csv_parse(decode(b"a,\x00", "utf-8"))[0][1] == decode(b"\x00", "utf-8")`, "true"},
+		{`csv_parse(decode(b"a,b\n\"c", "utf-8"))`, "ERROR: invalid csv at line 2: unterminated quoted field"},
+		{`csv_parse(decode(b"\"a\"b", "utf-8"))`, "ERROR: invalid csv at line 1: unexpected 'b' after quoted field"},
+		{`csv_parse(decode(b"a,b\n1\n", "utf-8"), {"header": true})`, "ERROR: invalid csv at line 2: 1 fields, but the header has 2"},
+		{`csv_format([{"a": 1}, {"b": 2}])`, "ERROR: row 2 of csv has the key b not in the header"},
+		{`csv_format([[[1]]])`, "ERROR: cannot write ARRAY as csv field in row 1"},
+		{`csv_format([1])`, "ERROR: row 1 of csv must be ARRAY, TUPLE or HASH, got INTEGER"},
+		{`csv_format([["a,b"]], {"quoting": "none"})`, `ERROR: field "a,b" of row 1 of csv needs quoting`},
+		{`csv_parse("a", {"delimiter": ";;"})`, `ERROR: delimiter of ` + "`csv_parse`" + ` must be a character other than newlines, got ";;"`},
+		{`csv_parse("a", {"delimiter": ""})`, `ERROR: delimiter of ` + "`csv_parse`" + ` must be a character other than newlines, got ""`},
+		{`csv_format([["a"]], {"delimiter": decode(b"\xef\xbf\xbd", "utf-8")})`, "ERROR: delimiter of `csv_format` must be a character other than newlines, got \"\ufffd\""},
+		{`csv_parse("a", {"sep": ";"})`, "ERROR: unknown option of `csv_parse`: sep"},
+		{`csv_open("no/such/file.csv")`, "ERROR: open no/such/file.csv: no such file or directory"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+
+	// the input fails after the first row, which is not taken for the end of the input
+	in := bufio.NewReader(iotest.TimeoutReader(strings.NewReader("a,b\nc,d")))
+	options, _ := getCSVOptions(nil, "csv_parse")
+	_, err := collectElements(newCSVIterator(in, nil, options))
+	if err == nil || err.Inspect() != "ERROR: could not read csv at line 2: timeout" {
+		t.Errorf("wrong error of reading. got=%v", err)
+	}
+}
+
+func TestCSVOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rows.csv")
+	// the broken last row is not read, as long as the rows before it are enough
+	content := "id,name\n1,ann\n2,\"bob\nby\"\n3,cid\n4,\"broken\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{fmt.Sprintf(`array(take(csv_open("%s", {"header": true}), 3))`, path), "[{id: 1, name: ann}, {id: 2, name: bob\nby}, {id: 3, name: cid}]"},
+		{fmt.Sprintf(`reduce(take(csv_open("%s"), 2), 0, fn(n, row) { n + len(row) })`, path), "4"},
+		{fmt.Sprintf(`array(csv_open("%s"))`, path), "ERROR: invalid csv at line 6: unterminated quoted field"},
+		{fmt.Sprintf(`fn head(path) { let rows = csv_open(path); defer close(rows); next(rows) } head("%s")`, path), "[id, name]"},
+		{fmt.Sprintf(`let rows = csv_open("%s"); next(rows); close(rows); next(rows, null)`, path), "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+
+	rows := builtins["csv_open"].Fn(&object.String{Value: path}).(*object.Iter)
+	rows.Next()
+	file := rows.Source.(*csvIterator).closer.(*os.File)
+	if result := builtins["close"].Fn(rows); result != NULL {
+		t.Fatalf("close failed. got=%q", result.Inspect())
+	}
+	if _, err := file.Read(make([]byte, 1)); err == nil {
+		t.Errorf("file is still open after close")
+	}
+
+	// reading a part of the rows releases the file as well
+	rows = builtins["csv_open"].Fn(&object.String{Value: path}).(*object.Iter)
+	file = rows.Source.(*csvIterator).closer.(*os.File)
+	taken := builtins["array"].Fn(builtins["take"].Fn(rows, &object.Integer{Value: 1}))
+	if taken.Inspect() != "[[id, name]]" {
+		t.Fatalf("wrong rows taken. got=%q", taken.Inspect())
+	}
+	if _, err := file.Read(make([]byte, 1)); err == nil {
+		t.Errorf("file is still open after a partial read")
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`len("héllo")`, "5"},
+		{`bytelen("héllo")`, "6"},
+		{`len("日本語")`, "3"},
+		{`["héllo"[1], "héllo"[4], "héllo"[5]]`, "[é, o, null]"},
+		{`"日本語"[-1]`, "null"},
+		{`["日本語"[0], "日本語"[2], "日本語"[3]]`, "[日, 語, null]"},
+		{`["aé日b"[2], "aé日b"[3], "ab"[1], "ab"[2]]`, "[日, b, b, null]"},
+		{`[first("éa"), last("aé"), rest("éa")]`, "[é, é, a]"},
+		{`"héllo"[1..3]`, "él"},
+		{`"日本語"[1..10]`, "本語"},
+		{`array("héllo")`, "[h, é, l, l, o]"},
+		{`"é" < "ê"`, "true"},
+		{`let h = {"héllo": 1}; h["h" + "éllo"]`, "1"},
+		{`len("😀")`, "1"},
+		{`encode("é", "utf-8")`, `b"\xc3\xa9"`},
+		{`len(graphemes("éa"))`, "2"},
+		{`graphemes(decode(b"e\xcc\x81a", "utf-8"))`, "[é, a]"},
+		{`len(graphemes(decode(b"\xf0\x9f\x91\x8d\xf0\x9f\x8f\xbd", "utf-8")))`, "1"},
+		{`len(graphemes(decode(b"\xf0\x9f\x91\xa9\xe2\x80\x8d\xf0\x9f\x92\xbb", "utf-8")))`, "1"},
+		{`len(graphemes(decode(b"\xf0\x9f\x87\xaf\xf0\x9f\x87\xb5\xf0\x9f\x87\xab\xf0\x9f\x87\xb7", "utf-8")))`, "2"},
+		{`graphemes(decode(b"a\r\nb", "utf-8"))`, "[a, \r\n, b]"},
+		{`graphemes("")`, "[]"},
+		{"bytelen(1)", "ERROR: argument to `bytelen` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"12.50d", "12.50"},
+		{"0.05d", "0.05"},
+		{"-0.05d", "-0.05"},
+		{"12.50d + 1", "13.50"},
+		{"0.1d + 0.2d", "0.3"},
+		{"10 - 0.01d", "9.99"},
+		{"19.99d * 3", "59.97"},
+		{"1.10d * 1.10d", "1.2100"},
+		{"10.00d / 4", "2.50"},
+		{"1d / 3", "0.3333333333333333"},
+		{"-2d / 3", "-0.6666666666666667"},
+		{"9223372036854775808 + 0.5d", "9223372036854775808.5"},
+		{"round(2.345d, 2)", "2.34"},
+		{"round(2.355d, 2)", "2.36"},
+		{`round(2.345d, 2, "half_up")`, "2.35"},
+		{`round(2.345d, 2, "half_down")`, "2.34"},
+		{`round(2.341d, 2, "up")`, "2.35"},
+		{`round(2.349d, 2, "down")`, "2.34"},
+		{`round(-2.341d, 2, "ceiling")`, "-2.34"},
+		{`round(-2.341d, 2, "floor")`, "-2.35"},
+		{"round(7, 2)", "7.00"},
+		{`decimal("3.14")`, "3.14"},
+		{"decimal(5)", "5"},
+		{"0.1d + 0.2d == 0.3d", true},
+		{"1.0d == 1", true},
+		{"1.5d < 2", true},
+		{"2.50d >= 2.5d", true},
+		{"[1.0d, 2] == [1, 2.00d]", true},
+		{`let h = {1: "one", 2.50d: "x"}; h[1.00d] + h[2.5d]`, "onex"},
+		{"1d / 0", "division by zero"},
+		{`1.5d + "a"`, "type mismatch: DECIMAL + STRING"},
+		{`round(1.5d, -1)`, "number of places must not be negative, got -1"},
+		{`round(1.5d, 10000) == 1.5d`, true},
+		{`round(1.5d, 10001)`, "number of places must be at most 10000, got 10001"},
+		{`round(1.5d, 9223372036854775807)`, "number of places must be at most 10000, got 9223372036854775807"},
+		{`round(1.5d, 0, "nearest")`, "unknown rounding mode: nearest"},
+		{`decimal("1.2.3")`, `could not parse "1.2.3" as decimal`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.Decimal:
+				if obj.Inspect() != expected {
+					t.Errorf("wrong value for %q. want=%s, got=%s", tt.input, expected, obj.Inspect())
+				}
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong value for %q. want=%q, got=%q", tt.input, expected, obj.Value)
+				}
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, expected, obj.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestDecimalPrecision(t *testing.T) {
+	DecimalPrecision = 2
+	DecimalRounding = object.RoundDown
+	defer func() {
+		DecimalPrecision = 16
+		DecimalRounding = object.RoundHalfEven
+	}()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2d / 3", "0.66"},
+		{"1.0000d / 3", "0.3333"},
+		{"round(2.345d, 2)", "2.34"},
+		{"round(2.355d, 2)", "2.35"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong value for %q. want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"#{}", "#{}"},
+		{"#{3, 1, 2, 1}", "#{1, 2, 3}"},
+		{`#{"b", "a", 2, true, 1}`, "#{1, 2, true, a, b}"},
+		{"#{...[3, 1], ...#{2}}", "#{1, 2, 3}"},
+		{"#{1, 2} | #{2, 3}", "#{1, 2, 3}"},
+		{"#{1, 2, 3} & #{2, 3, 4}", "#{2, 3}"},
+		{"#{1, 2, 3} - #{2}", "#{1, 3}"},
+		{"#{1} | #{2} & #{3}", "#{1}"},
+		{"set([2, 1, 2])", "#{1, 2}"},
+		{"set(1..4)", "#{1, 2, 3}"},
+		{"#{#{1, 2}, #{2, 1}}", "#{#{1, 2}}"},
+		{"#{1.0d, 1}", "#{1.0}"},
+		{"array(#{3, 1, 2})", "[1, 2, 3]"},
+		{"#{1, 2}[1]", true},
+		{"#{1, 2}[3]", false},
+		{"#{1, 2} == #{2, 1}", true},
+		{"#{1, 2} != #{1}", true},
+		{"#{1} == [1]", false},
+		{"len(#{1, 1, 2})", 2},
+		{"reduce(#{1, 2, 3}, 0, fn(acc, x) { acc + x })", 6},
+		{`let seen = {#{1, 2}: "pair"}; seen[#{2, 1}]`, "pair"},
+		{"#{{}}", "unusable as set element: HASH"},
+		{"#{1}[{}]", "unusable as set element: HASH"},
+		{"#{1} < #{2}", "unknown operator: SET < SET"},
+		{"#{1} | [1]", "type mismatch: SET | ARRAY"},
+		{"1 | 2", "unknown operator: INTEGER | INTEGER"},
+		{"set(1)", "argument to `set` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, expected, obj.Message)
+				}
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong value for %q. want=%q, got=%q", tt.input, expected, obj.Value)
+				}
+			default:
+				if evaluated.Inspect() != expected {
+					t.Errorf("wrong value for %q. want=%s, got=%s", tt.input, expected, evaluated.Inspect())
+				}
+			}
+		}
+	}
+}
+
+func TestTuples(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`(1, "a", true)`, "(1, a, true)"},
+		{"(1,)", "(1,)"},
+		{"()", "()"},
+		{"(1)", 1},
+		{"let t = (1, 2); t[0] + t[1]", 3},
+		{"(1, 2)[2]", nil},
+		{"(1, 2, 3)[1..3]", "(2, 3)"},
+		{"len((1, 2, 3))", 3},
+		{"array((1, 2))", "[1, 2]"},
+		{"(1, 2) == (1, 2)", true},
+		{"(1, 2) == [1, 2]", false},
+		{"(1, 2) < (1, 3)", true},
+		{`let h = {(1, "a"): "pair"}; h[(1, "a")]`, "pair"},
+		{"#{(1, 2), (1, 2)}", "#{(1, 2)}"},
+		{"let divmod = fn(a, b) { return a / b, a - a / b * b; }; let (q, r) = divmod(7, 2); [q, r]", "[3, 1]"},
+		{"fn pair() { return 1, 2 } let (x, y) = pair(); x * 10 + y", 12},
+		{"let (x, y) = [1, 2]; y", 2},
+		{"let (a, b) = (1, 2, 3);", "cannot unpack 3 values into 2 names"},
+		{"let (a, b) = 1;", "cannot unpack INTEGER"},
+		{"{(1, {}): 1}", "unusable as hash key: TUPLE"},
+		{"(1, 2) + (3,)", "unknown operator: TUPLE + TUPLE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case nil:
+			testNullObject(t, evaluated)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, expected, obj.Message)
+				}
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong value for %q. want=%q, got=%q", tt.input, expected, obj.Value)
+				}
+			default:
+				if evaluated.Inspect() != expected {
+					t.Errorf("wrong value for %q. want=%s, got=%s", tt.input, expected, evaluated.Inspect())
+				}
+			}
+		}
+	}
+}
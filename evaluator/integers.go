@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/object"
+)
+
+// OverflowMode type decides what the integer arithmetic does when the result does not fit in int64
+type OverflowMode int
+
+// overflow modes
+const (
+	PromoteOnOverflow OverflowMode = iota // the result is promoted to a BigInt
+	TrapOnOverflow                        // the result is an OverflowError, and big integer literals are rejected
+)
+
+// OverflowPolicy is the overflow mode of the evaluator
+var OverflowPolicy = PromoteOnOverflow
+
+// evalIntegerArithmetic function applies +, -, * or / to the integers, detecting the overflow of int64
+func evalIntegerArithmetic(operator string, left, right *object.Integer) object.Object {
+	leftVal := left.Value
+	rightVal := right.Value
+	switch operator {
+	case "+":
+		sum := leftVal + rightVal
+		if (leftVal^sum)&(rightVal^sum) >= 0 { // overflowed only if both operands differ in sign from the sum
+			return &object.Integer{Value: sum}
+		}
+	case "-":
+		diff := leftVal - rightVal
+		if (leftVal^rightVal)&(leftVal^diff) >= 0 {
+			return &object.Integer{Value: diff}
+		}
+	case "*":
+		product := leftVal * rightVal
+		if leftVal == 0 || (product/leftVal == rightVal && !(leftVal == -1 && rightVal == math.MinInt64)) {
+			return &object.Integer{Value: product}
+		}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if !(leftVal == math.MinInt64 && rightVal == -1) {
+			return &object.Integer{Value: leftVal / rightVal}
+		}
+	}
+	if OverflowPolicy == TrapOnOverflow {
+		return newErrorOfKind(object.OVERFLOW_ERROR, "integer overflow: %d %s %d", leftVal, operator, rightVal)
+	}
+	return evalBigIntInfixExpression(operator, left, right)
+}
+
+// evalBigIntInfixExpression function
+// this function is called when left and right are integers, and at least one of them is *object.BigInt type or the result overflows
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.ToBigInt(left)
+	rightVal, _ := object.ToBigInt(right)
+	switch operator {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInt(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return normalizeBigInt(new(big.Int).Quo(leftVal, rightVal)) // truncated as the division of int64
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalBigIntegerLiteral function
+func evalBigIntegerLiteral(literal *ast.BigIntegerLiteral) object.Object {
+	if OverflowPolicy == TrapOnOverflow {
+		return newErrorOfKind(object.OVERFLOW_ERROR, "integer overflow: %s", literal.Value)
+	}
+	return &object.BigInt{Value: literal.Value}
+}
+
+// normalizeBigInt function demotes the value to an Integer if it fits in int64
+func normalizeBigInt(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInt{Value: value}
+}
+
+// isInteger function reports whether obj is an Integer or a BigInt
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
@@ -0,0 +1,388 @@
+package evaluator
+
+import (
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/object"
+)
+
+// iterationBuiltins defines the builtin functions consuming iterables
+// they are registered to builtins by init, since they apply Monkey functions
+var iterationBuiltins = map[string]*object.Builtin{
+	"iter": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			iterator, err := getIterator(args[0], "iter")
+			if err != nil {
+				return err
+			}
+			if obj, ok := iterator.(object.Object); ok { // generators and iterators are passed as they are
+				return obj
+			}
+			return &object.Iter{Source: iterator}
+		},
+	},
+	"next": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1 or 2", len(args))
+			}
+			iterator, ok := args[0].(object.Iterator)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `next` not supported, got %s", args[0].Type())
+			}
+			element, ok := iterator.Next()
+			if !ok {
+				if len(args) == 2 {
+					return args[1]
+				}
+				return newErrorOfKind(object.STOP_ITERATION, "iterator is exhausted")
+			}
+			return element
+		},
+	},
+	// close(it) releases the resources of an iterator which is not exhausted, as in defer close(g)
+	"close": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			iterator, ok := args[0].(object.Iterator)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `close` not supported, got %s", args[0].Type())
+			}
+			if err := closeIterator(iterator); err != nil {
+				return err
+			}
+			return NULL
+		},
+	},
+	"take": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			iterator, err := getIterator(args[0], "take")
+			if err != nil {
+				return err
+			}
+			n, ok := args[1].(*object.Integer)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `take` not supported, got %s", args[1].Type())
+			}
+			return &object.Iter{Source: &takeIterator{source: iterator, remaining: n.Value}}
+		},
+	},
+	"map": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			iterator, err := getIterator(args[0], "map")
+			if err != nil {
+				return err
+			}
+			return &object.Iter{Source: &mapIterator{source: iterator, fn: args[1]}}
+		},
+	},
+	"filter": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			iterator, err := getIterator(args[0], "filter")
+			if err != nil {
+				return err
+			}
+			return &object.Iter{Source: &filterIterator{source: iterator, fn: args[1]}}
+		},
+	},
+	"each": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			iterator, err := getIterator(args[0], "each")
+			if err != nil {
+				return err
+			}
+			for element, ok := iterator.Next(); ok; element, ok = iterator.Next() {
+				if isError(element) {
+					closeIterator(iterator)
+					return element
+				}
+				if result := applyFunction(args[1], []object.Object{element}); isError(result) {
+					closeIterator(iterator)
+					return result
+				}
+			}
+			if err := closeIterator(iterator); err != nil {
+				return err
+			}
+			return NULL
+		},
+	},
+	"reduce": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=3", len(args))
+			}
+			iterator, err := getIterator(args[0], "reduce")
+			if err != nil {
+				return err
+			}
+			accumulated := args[1]
+			for element, ok := iterator.Next(); ok; element, ok = iterator.Next() {
+				if isError(element) {
+					closeIterator(iterator)
+					return element
+				}
+				accumulated = applyFunction(args[2], []object.Object{accumulated, element})
+				if isError(accumulated) {
+					closeIterator(iterator)
+					return accumulated
+				}
+			}
+			if err := closeIterator(iterator); err != nil {
+				return err
+			}
+			return accumulated
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range iterationBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// getIterator function returns a new iterator over obj, or an error if obj is not iterable
+func getIterator(obj object.Object, name string) (object.Iterator, *object.Error) {
+	iterable, ok := obj.(object.Iterable)
+	if !ok {
+		return nil, newErrorOfKind(object.TYPE_ERROR, "argument to `%s` not supported, got %s", name, obj.Type())
+	}
+	return iterable.Iterator(), nil
+}
+
+// collectElements function consumes the iterator and returns all the elements
+// the iterator is closed before returning, also when it fails, so that the resources behind it are released
+func collectElements(iterator object.Iterator) ([]object.Object, object.Object) {
+	elements := []object.Object{}
+	for element, ok := iterator.Next(); ok; element, ok = iterator.Next() {
+		if isError(element) {
+			closeIterator(iterator)
+			return nil, element
+		}
+		elements = append(elements, element)
+	}
+	if err := closeIterator(iterator); err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// closeIterator function closes the iterator if it holds resources, and returns the error of closing it, or nil
+func closeIterator(iterator object.Iterator) object.Object {
+	if closer, ok := iterator.(object.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// takeIterator struct produces at most remaining elements of the source
+// the source is closed once they are taken, so a generator behind it unwinds rather than waiting forever
+type takeIterator struct {
+	source    object.Iterator
+	remaining int64
+}
+
+// Next method of takeIterator struct
+func (it *takeIterator) Next() (object.Object, bool) {
+	if it.remaining <= 0 {
+		if err := closeIterator(it.source); err != nil {
+			return err, true
+		}
+		return nil, false
+	}
+	it.remaining--
+	return it.source.Next()
+}
+
+// Close method of takeIterator struct
+func (it *takeIterator) Close() object.Object {
+	return closeIterator(it.source)
+}
+
+// mapIterator struct applies fn to each element of the source
+type mapIterator struct {
+	source object.Iterator
+	fn     object.Object
+}
+
+// Next method of mapIterator struct
+func (it *mapIterator) Next() (object.Object, bool) {
+	element, ok := it.source.Next()
+	if !ok || isError(element) {
+		return element, ok
+	}
+	return applyFunction(it.fn, []object.Object{element}), true
+}
+
+// Close method of mapIterator struct
+func (it *mapIterator) Close() object.Object {
+	return closeIterator(it.source)
+}
+
+// filterIterator struct produces the elements of the source for which fn is truthy
+type filterIterator struct {
+	source object.Iterator
+	fn     object.Object
+}
+
+// Next method of filterIterator struct
+func (it *filterIterator) Next() (object.Object, bool) {
+	for {
+		element, ok := it.source.Next()
+		if !ok || isError(element) {
+			return element, ok
+		}
+		keep := applyFunction(it.fn, []object.Object{element})
+		if isError(keep) {
+			return keep, true
+		}
+		if isTruthy(keep) {
+			return element, true
+		}
+	}
+}
+
+// Close method of filterIterator struct
+func (it *filterIterator) Close() object.Object {
+	return closeIterator(it.source)
+}
+
+// generatorIterator struct runs the body of a generator function in its own goroutine
+/*
+	The goroutine and the caller of Next run in lockstep: the body runs only between a call of Next and the next yield.
+	A generator which is not consumed to the end keeps its goroutine blocked on resume until it is closed.
+	Closing it makes the pending yield return from the body, which runs the deferred calls and finally blocks on the way out.
+*/
+type generatorIterator struct {
+	run    func() // starts the body on the first call of Next
+	resume chan struct{}
+	yields chan object.Object
+	closed chan struct{} // closed by Close, which every yield after it returns on
+	done   bool
+}
+
+// newGenerator function creates a generator from a call of the generator function fn
+// env is the environment of the call, with the parameters already bound
+func newGenerator(fn *object.Function, env *object.Environment) *object.Generator {
+	it := &generatorIterator{
+		resume: make(chan struct{}),
+		yields: make(chan object.Object),
+		closed: make(chan struct{}),
+	}
+	frame := env.Frame()
+	frame.Yield = func(value object.Object) bool {
+		select {
+		case it.yields <- value:
+		case <-it.closed:
+			return false
+		}
+		select {
+		case <-it.resume:
+			return true
+		case <-it.closed:
+			return false
+		}
+	}
+	it.run = func() {
+		evaluated := evalBlockStatement(fn.Body, env)
+		evaluated = runDeferredCalls(frame, evaluated)
+		if isError(evaluated) {
+			it.yields <- evaluated
+		}
+		close(it.yields)
+	}
+	return &object.Generator{Name: fn.Name, Source: it}
+}
+
+// Next method of generatorIterator struct
+func (it *generatorIterator) Next() (object.Object, bool) {
+	if it.done {
+		return nil, false
+	}
+	if it.run != nil {
+		go it.run()
+		it.run = nil
+	} else {
+		it.resume <- struct{}{}
+	}
+	value, ok := <-it.yields
+	if !ok || isError(value) {
+		it.done = true
+	}
+	return value, ok
+}
+
+// Close method of generatorIterator struct
+// it waits until the body has unwound, and returns the error raised on the way, such as by a deferred call
+func (it *generatorIterator) Close() object.Object {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	if it.run != nil { // the body has not started
+		it.run = nil
+		return nil
+	}
+	close(it.closed)
+	var err object.Object
+	for value := range it.yields {
+		if isError(value) {
+			err = value
+		}
+	}
+	return err
+}
+
+// evalYieldStatement function produces the value from the enclosing generator, and waits until it is resumed
+// a delegating yield produces every element of the iterable one by one
+// if the generator is closed meanwhile, the yield returns from the body, closing the iterable delegated to
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment) object.Object {
+	frame := env.Frame()
+	if frame == nil || frame.Yield == nil {
+		return newError("yield outside generator")
+	}
+	value := Eval(ys.Value, env)
+	if isError(value) {
+		return value
+	}
+	if !ys.Delegate {
+		if !frame.Yield(value) {
+			return &object.ReturnValue{Value: NULL}
+		}
+		return nil
+	}
+	iterator, err := getIterator(value, "yield")
+	if err != nil {
+		return err
+	}
+	for element, ok := iterator.Next(); ok; element, ok = iterator.Next() {
+		if isError(element) {
+			closeIterator(iterator)
+			return element
+		}
+		if !frame.Yield(element) {
+			if err := closeIterator(iterator); err != nil {
+				return err
+			}
+			return &object.ReturnValue{Value: NULL}
+		}
+	}
+	return nil
+}
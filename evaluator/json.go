@@ -0,0 +1,575 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/BOBO1997/monkey/object"
+)
+
+// jsonBuiltins defines the builtin functions for JSON
+/*
+	JSON null, booleans, strings, arrays and objects are null, booleans, strings, arrays and hashes of Monkey,
+	and the numbers are integers (big integers if they do not fit), or decimals if they have a fraction or an exponent.
+	The hashes keep the order of the keys of the objects, and the last of the duplicated keys wins.
+*/
+var jsonBuiltins = map[string]*object.Builtin{
+	"json_parse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `json_parse` not supported, got %s", args[0].Type())
+			}
+			return parseJSON(str.Value)
+		},
+	},
+	// json_stringify(value, options) writes the value in JSON, where the options are a hash of
+	// "indent", the number of spaces (or the string) to indent the nested values with, and
+	// "sort_keys", whether the keys of the hashes are written in order instead of insertion order
+	"json_stringify": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1..2", len(args))
+			}
+			w := &jsonWriter{visiting: map[object.Object]bool{}}
+			if len(args) == 2 {
+				if err := w.setOptions(args[1]); err != nil {
+					return err
+				}
+			}
+			if err := w.write(args[0], "$", 0); err != nil {
+				return err
+			}
+			return &object.String{Value: w.out.String()}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range jsonBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// maxJSONDepth is the deepest nesting of the arrays and objects which json_parse accepts
+const maxJSONDepth = 10000
+
+// maxJSONExponent is the largest exponent of the numbers which json_parse accepts, as the decimals keep all the digits
+const maxJSONExponent = 10000
+
+// jsonParser struct is a recursive descent parser of JSON
+type jsonParser struct {
+	input string
+	pos   int // the byte offset of the next character
+}
+
+// parseJSON function parses the whole input as a JSON value
+func parseJSON(input string) object.Object {
+	p := &jsonParser{input: input}
+	value := p.parseValue(0)
+	if isError(value) {
+		return value
+	}
+	p.skipWhitespace()
+	if p.pos < len(p.input) {
+		return p.unexpected()
+	}
+	return value
+}
+
+// errorf method of jsonParser struct returns the error at the current position
+// the position is given as the line and the column (counted in characters) from 1, and the offset in bytes from 0
+func (p *jsonParser) errorf(format string, a ...interface{}) *object.Error {
+	line, column := 1, 1
+	for _, r := range p.input[:p.pos] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return newError("invalid json at line %d, column %d (offset %d): %s", line, column, p.pos, fmt.Sprintf(format, a...))
+}
+
+// unexpected method of jsonParser struct returns the error of the character at the current position
+func (p *jsonParser) unexpected() *object.Error {
+	if p.pos >= len(p.input) {
+		return p.errorf("unexpected end of input")
+	}
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.errorf("unexpected character %q", r)
+}
+
+// skipWhitespace method of jsonParser struct
+func (p *jsonParser) skipWhitespace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseValue method of jsonParser struct parses a value at depth of nesting
+func (p *jsonParser) parseValue(depth int) object.Object {
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return p.unexpected()
+	}
+	switch ch := p.input[p.pos]; {
+	case ch == '{':
+		return p.parseObject(depth + 1)
+	case ch == '[':
+		return p.parseArray(depth + 1)
+	case ch == '"':
+		str, err := p.parseString()
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: str}
+	case ch == '-' || ('0' <= ch && ch <= '9'):
+		return p.parseNumber()
+	case strings.HasPrefix(p.input[p.pos:], "true"):
+		p.pos += len("true")
+		return TRUE
+	case strings.HasPrefix(p.input[p.pos:], "false"):
+		p.pos += len("false")
+		return FALSE
+	case strings.HasPrefix(p.input[p.pos:], "null"):
+		p.pos += len("null")
+		return NULL
+	default:
+		return p.unexpected()
+	}
+}
+
+// parseObject method of jsonParser struct parses an object into a hash
+func (p *jsonParser) parseObject(depth int) object.Object {
+	if depth > maxJSONDepth {
+		return p.errorf("nested too deeply")
+	}
+	p.pos++ // {
+	hash := object.NewHash()
+	p.skipWhitespace()
+	if p.pos < len(p.input) && p.input[p.pos] == '}' {
+		p.pos++
+		return hash
+	}
+	for {
+		p.skipWhitespace()
+		if p.pos >= len(p.input) || p.input[p.pos] != '"' {
+			return p.unexpected()
+		}
+		key, err := p.parseString()
+		if err != nil {
+			return err
+		}
+		p.skipWhitespace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+			return p.unexpected()
+		}
+		p.pos++
+		value := p.parseValue(depth)
+		if isError(value) {
+			return value
+		}
+		hash.Set(&object.String{Value: key}, value)
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return p.unexpected()
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return hash
+		default:
+			return p.unexpected()
+		}
+	}
+}
+
+// parseArray method of jsonParser struct parses an array
+func (p *jsonParser) parseArray(depth int) object.Object {
+	if depth > maxJSONDepth {
+		return p.errorf("nested too deeply")
+	}
+	p.pos++ // [
+	elements := []object.Object{}
+	p.skipWhitespace()
+	if p.pos < len(p.input) && p.input[p.pos] == ']' {
+		p.pos++
+		return object.NewArray(elements)
+	}
+	for {
+		element := p.parseValue(depth)
+		if isError(element) {
+			return element
+		}
+		elements = append(elements, element)
+		p.skipWhitespace()
+		if p.pos >= len(p.input) {
+			return p.unexpected()
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return object.NewArray(elements)
+		default:
+			return p.unexpected()
+		}
+	}
+}
+
+// parseString method of jsonParser struct parses a string, decoding the escape sequences
+func (p *jsonParser) parseString() (string, *object.Error) {
+	p.pos++ // "
+	var out strings.Builder
+	for {
+		if p.pos >= len(p.input) {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.input[p.pos]
+		switch {
+		case ch == '"':
+			p.pos++
+			return out.String(), nil
+		case ch < 0x20:
+			return "", p.errorf("control character %q in string", ch)
+		case ch != '\\':
+			out.WriteByte(ch)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.input) {
+			return "", p.errorf("unterminated string")
+		}
+		escape := p.input[p.pos+1]
+		switch escape {
+		case '"', '\\', '/':
+			out.WriteByte(escape)
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'u':
+			r, ok := p.parseUnicodeEscape(p.pos)
+			if !ok {
+				end := p.pos + 6
+				if end > len(p.input) {
+					end = len(p.input)
+				}
+				return "", p.errorf("invalid escape %q", p.input[p.pos:end])
+			}
+			p.pos += 6
+			if utf16.IsSurrogate(r) { // the pair of the surrogates, or the replacement character if it is broken
+				if low, ok := p.parseUnicodeEscape(p.pos); ok {
+					if decoded := utf16.DecodeRune(r, low); decoded != utf8.RuneError {
+						r = decoded
+						p.pos += 6
+					}
+				}
+				if utf16.IsSurrogate(r) {
+					r = utf8.RuneError
+				}
+			}
+			out.WriteRune(r)
+			continue
+		default:
+			return "", p.errorf("invalid escape %q", p.input[p.pos:p.pos+2])
+		}
+		p.pos += 2
+	}
+}
+
+// parseUnicodeEscape method of jsonParser struct decodes the escape \uXXXX at pos
+func (p *jsonParser) parseUnicodeEscape(pos int) (rune, bool) {
+	if len(p.input) < pos+6 || p.input[pos:pos+2] != `\u` {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(p.input[pos+2:pos+6], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(code), true
+}
+
+// parseNumber method of jsonParser struct parses a number, as -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (p *jsonParser) parseNumber() object.Object {
+	start := p.pos
+	if p.input[p.pos] == '-' {
+		p.pos++
+	}
+	switch {
+	case p.pos < len(p.input) && p.input[p.pos] == '0':
+		p.pos++
+	case !p.skipDigits():
+		return p.unexpected()
+	}
+	hasFraction := p.pos < len(p.input) && p.input[p.pos] == '.'
+	if hasFraction {
+		p.pos++
+		if !p.skipDigits() {
+			return p.unexpected()
+		}
+	}
+	mantissa := p.input[start:p.pos]
+	exponent := 0
+	hasExponent := p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E')
+	if hasExponent {
+		p.pos++
+		sign := p.pos
+		if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return p.unexpected()
+		}
+		var err error
+		exponent, err = strconv.Atoi(p.input[sign:p.pos])
+		if err != nil || exponent < -maxJSONExponent || maxJSONExponent < exponent {
+			p.pos = start
+			return p.errorf("exponent out of range")
+		}
+	}
+	if !hasFraction && !hasExponent {
+		if value, err := strconv.ParseInt(mantissa, 10, 64); err == nil {
+			return &object.Integer{Value: value}
+		}
+		value, _ := new(big.Int).SetString(mantissa, 10)
+		return &object.BigInt{Value: value}
+	}
+	d, err := object.ParseDecimal(mantissa)
+	if err != nil {
+		return p.errorf("%s", err)
+	}
+	if exponent > 0 {
+		shift := exponent - d.Scale
+		if shift > 0 {
+			d = &object.Decimal{Value: new(big.Int).Mul(d.Value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))}
+		} else {
+			d = &object.Decimal{Value: d.Value, Scale: -shift}
+		}
+	} else {
+		d = &object.Decimal{Value: d.Value, Scale: d.Scale - exponent}
+	}
+	return d
+}
+
+// skipDigits method of jsonParser struct skips the digits, and reports whether there is any
+func (p *jsonParser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.input) && '0' <= p.input[p.pos] && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// jsonWriter struct writes the values in JSON
+type jsonWriter struct {
+	out      strings.Builder
+	indent   string // no newlines nor indentation if empty
+	sortKeys bool
+	visiting map[object.Object]bool // the arrays and hashes being written, to find the cycles
+}
+
+// setOptions method of jsonWriter struct reads the options of json_stringify
+func (w *jsonWriter) setOptions(obj object.Object) *object.Error {
+	options, ok := obj.(*object.Hash)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "options of `json_stringify` must be HASH, got %s", obj.Type())
+	}
+	for _, pair := range options.Pairs() {
+		switch key := pair.Key.Inspect(); {
+		case pair.Key.Type() == object.STRING_OBJ && key == "indent":
+			switch indent := pair.Value.(type) {
+			case *object.Integer:
+				if indent.Value < 0 || 16 < indent.Value {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "indent of `json_stringify` out of range: %d", indent.Value)
+				}
+				w.indent = strings.Repeat(" ", int(indent.Value))
+			case *object.String:
+				w.indent = indent.Value
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "indent of `json_stringify` must be INTEGER or STRING, got %s", pair.Value.Type())
+			}
+		case pair.Key.Type() == object.STRING_OBJ && key == "sort_keys":
+			w.sortKeys = isTruthy(pair.Value)
+		default:
+			return newErrorOfKind(object.ARGUMENT_ERROR, "unknown option of `json_stringify`: %s", pair.Key.Inspect())
+		}
+	}
+	return nil
+}
+
+// write method of jsonWriter struct writes the value at the path, such as $.items[0], at depth of nesting
+func (w *jsonWriter) write(obj object.Object, path string, depth int) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Null:
+		w.out.WriteString("null")
+	case *object.Boolean, *object.Integer, *object.BigInt, *object.Decimal:
+		w.out.WriteString(obj.Inspect())
+	case *object.String:
+		writeJSONString(&w.out, obj.Value)
+	case *object.Array:
+		return w.writeArray(obj, obj.Elements(), path, depth)
+	case *object.Tuple:
+		return w.writeArray(obj, obj.Elements, path, depth)
+	case *object.Hash:
+		pairs := obj.Pairs()
+		for _, pair := range pairs {
+			if pair.Key.Type() != object.STRING_OBJ {
+				return newErrorOfKind(object.TYPE_ERROR, "cannot write %s as json key at %s", pair.Key.Type(), path)
+			}
+		}
+		if w.sortKeys {
+			sort.SliceStable(pairs, func(i, j int) bool {
+				return pairs[i].Key.(*object.String).Value < pairs[j].Key.(*object.String).Value
+			})
+		}
+		return w.writeObject(obj, pairs, path, depth)
+	case *object.Record:
+		pairs := make([]object.HashPair, len(obj.Values))
+		for i, value := range obj.Values {
+			pairs[i] = object.HashPair{Key: &object.String{Value: obj.Struct.Fields[i]}, Value: value}
+		}
+		if w.sortKeys {
+			sort.SliceStable(pairs, func(i, j int) bool {
+				return pairs[i].Key.(*object.String).Value < pairs[j].Key.(*object.String).Value
+			})
+		}
+		return w.writeObject(obj, pairs, path, depth)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "cannot write %s as json at %s", obj.Type(), path)
+	}
+	return nil
+}
+
+// writeArray method of jsonWriter struct
+func (w *jsonWriter) writeArray(obj object.Object, elements []object.Object, path string, depth int) *object.Error {
+	if w.visiting[obj] {
+		return newError("cannot write cyclic value as json at %s", path)
+	}
+	w.visiting[obj] = true
+	defer delete(w.visiting, obj)
+
+	w.out.WriteString("[")
+	for i, element := range elements {
+		if i > 0 {
+			w.out.WriteString(",")
+		}
+		w.newline(depth + 1)
+		if err := w.write(element, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	if len(elements) > 0 {
+		w.newline(depth)
+	}
+	w.out.WriteString("]")
+	return nil
+}
+
+// writeObject method of jsonWriter struct, where the keys of the pairs are strings
+func (w *jsonWriter) writeObject(obj object.Object, pairs []object.HashPair, path string, depth int) *object.Error {
+	if w.visiting[obj] {
+		return newError("cannot write cyclic value as json at %s", path)
+	}
+	w.visiting[obj] = true
+	defer delete(w.visiting, obj)
+
+	w.out.WriteString("{")
+	for i, pair := range pairs {
+		if i > 0 {
+			w.out.WriteString(",")
+		}
+		w.newline(depth + 1)
+		key := pair.Key.(*object.String).Value
+		writeJSONString(&w.out, key)
+		w.out.WriteString(":")
+		if w.indent != "" {
+			w.out.WriteString(" ")
+		}
+		if err := w.write(pair.Value, jsonPath(path, key), depth+1); err != nil {
+			return err
+		}
+	}
+	if len(pairs) > 0 {
+		w.newline(depth)
+	}
+	w.out.WriteString("}")
+	return nil
+}
+
+// newline method of jsonWriter struct starts a new line indented to depth, if the output is indented
+func (w *jsonWriter) newline(depth int) {
+	if w.indent == "" {
+		return
+	}
+	w.out.WriteString("\n")
+	w.out.WriteString(strings.Repeat(w.indent, depth))
+}
+
+// jsonPath function returns the path of the key of the object at path, such as $.name or $["first name"]
+func jsonPath(path, key string) string {
+	for i, r := range key {
+		if !(isJSONPathLetter(r) || (i > 0 && '0' <= r && r <= '9')) {
+			return fmt.Sprintf("%s[%q]", path, key)
+		}
+	}
+	if key == "" {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
+
+// isJSONPathLetter function
+func isJSONPathLetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || r == '_'
+}
+
+// writeJSONString function writes the string quoted, escaping the quotes, the backslashes and the control characters
+func writeJSONString(out *strings.Builder, s string) {
+	out.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(out, `\u%04x`, r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+	out.WriteByte('"')
+}
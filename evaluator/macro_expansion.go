@@ -62,7 +62,7 @@ func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
 		}
 		args := quoteArgs(callExpression)
 		evalEnv := extendMacroEnv(macro, args)
-		evaluated := Eval(macro.Body, evalEnv)
+		evaluated := evalBlockStatement(macro.Body, evalEnv)
 		quote, ok := evaluated.(*object.Quote)
 		if !ok {
 			panic("we only support returning AST-nodes from macros")
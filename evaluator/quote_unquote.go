@@ -9,12 +9,21 @@ import (
 )
 
 func quote(node ast.Node, env *object.Environment) object.Object {
-	node = evalUnquoteCalls(node, env)
+	node, err := evalUnquoteCalls(node, env)
+	if err != nil {
+		return err
+	}
 	return &object.Quote{Node: node}
 }
 
-func evalUnquoteCalls(node ast.Node, env *object.Environment) ast.Node {
-	return ast.Modify(node, func(node ast.Node) ast.Node {
+// evalUnquoteCalls function replaces the unquote calls in node with their values
+// a value which has no literal (e.g. a function, or an array holding one) is an error
+func evalUnquoteCalls(node ast.Node, env *object.Environment) (ast.Node, object.Object) {
+	var err object.Object
+	modified := ast.Modify(node, func(node ast.Node) ast.Node {
+		if err != nil {
+			return node
+		}
 		if !isUnquoteCall(node) { // do nothing
 			return node
 		}
@@ -26,8 +35,18 @@ func evalUnquoteCalls(node ast.Node, env *object.Environment) ast.Node {
 			return node
 		}
 		unquoted := Eval(call.Arguments[0], env)
-		return convertObjectToAstNode(unquoted)
+		if isError(unquoted) {
+			err = unquoted
+			return node
+		}
+		converted := convertObjectToAstNode(unquoted)
+		if converted == nil {
+			err = newErrorOfKind(object.TYPE_ERROR, "argument to `unquote` not supported, got %s", unquoted.Type())
+			return node
+		}
+		return converted
 	})
+	return modified, err
 }
 
 func isUnquoteCall(node ast.Node) bool {
@@ -49,6 +68,25 @@ func convertObjectToAstNode(obj object.Object) ast.Node {
 			Token: tok,
 			Value: obj.Value,
 		}
+	case *object.BigInt:
+		tok := token.Token{
+			Type:    token.INT,
+			Literal: obj.Value.String(),
+		}
+		return &ast.BigIntegerLiteral{
+			Token: tok,
+			Value: obj.Value,
+		}
+	case *object.Decimal:
+		tok := token.Token{
+			Type:    token.DECIMAL,
+			Literal: obj.Inspect() + "d",
+		}
+		return &ast.DecimalLiteral{
+			Token: tok,
+			Value: obj.Value,
+			Scale: obj.Scale,
+		}
 	case *object.Boolean:
 		var tok token.Token
 		if obj.Value {
@@ -66,6 +104,71 @@ func convertObjectToAstNode(obj object.Object) ast.Node {
 			Token: tok,
 			Value: obj.Value,
 		}
+	case *object.String:
+		tok := token.Token{
+			Type:    token.STRING,
+			Literal: obj.Value,
+		}
+		return &ast.StringLiteral{
+			Token: tok,
+			Value: obj.Value,
+		}
+	case *object.Bytes:
+		return &ast.BytesLiteral{
+			Token: token.Token{Type: token.BYTES, Literal: obj.Inspect()},
+			Value: obj.Value,
+		}
+	case *object.Regex:
+		return &ast.RegexLiteral{
+			Token: token.Token{Type: token.REGEX, Literal: obj.Inspect()},
+			Value: obj.Value,
+		}
+	case *object.Array:
+		elements := make([]ast.Expression, obj.Len())
+		for i, element := range obj.Elements() {
+			converted, ok := convertObjectToAstNode(element).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			elements[i] = converted
+		}
+		return &ast.ArrayLiteral{
+			Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+			Elements: elements,
+		}
+	case *object.Tuple:
+		elements := make([]ast.Expression, len(obj.Elements))
+		for i, element := range obj.Elements {
+			converted, ok := convertObjectToAstNode(element).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			elements[i] = converted
+		}
+		return &ast.TupleLiteral{
+			Token:    token.Token{Type: token.LPAREN, Literal: "("},
+			Elements: elements,
+		}
+	case *object.Set:
+		elements := []ast.Expression{}
+		for _, element := range obj.Sorted() {
+			converted, ok := convertObjectToAstNode(element).(ast.Expression)
+			if !ok {
+				return nil
+			}
+			elements = append(elements, converted)
+		}
+		return &ast.SetLiteral{
+			Token:    token.Token{Type: token.HASHBRACE, Literal: "#{"},
+			Elements: elements,
+		}
+	case *object.Null:
+		return &ast.NullLiteral{
+			Token: token.Token{
+				Type:    token.NULL,
+				Literal: "null",
+			},
+		}
 	case *object.Quote:
 		return obj.Node
 	default:
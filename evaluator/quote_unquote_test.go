@@ -57,6 +57,42 @@ func TestQuoteUnquote(t *testing.T) {
 			`quote(unquote(4 + 4) + 8)`,
 			`(8 + 8)`,
 		},
+		{
+			`quote(unquote(null) ?? 8)`,
+			`(null ?? 8)`,
+		},
+		{
+			`let xs = [1, 2]; quote([0, ...unquote(xs)])`,
+			`[0, ...[1, 2]]`,
+		},
+		{
+			`quote(f(...unquote(push([], 3))))`,
+			`f(...[3])`,
+		},
+		{
+			`quote(unquote(9223372036854775807 + 1) + 1)`,
+			`(9223372036854775808 + 1)`,
+		},
+		{
+			`quote(unquote(12.50d + 1) * 2)`,
+			`(13.50d * 2)`,
+		},
+		{
+			`quote(unquote(#{3, 1} | #{2}))`,
+			`#{1, 2, 3}`,
+		},
+		{
+			`quote(unquote((1, "a")))`,
+			`(1, a)`,
+		},
+		{
+			`quote(unquote(b"a" + bytes([0, 34])))`,
+			`b"a\x00\""`,
+		},
+		{
+			`quote(unquote(regex("\d+" + decode(b"\x22", "ascii"))))`,
+			`r"\d+\""`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -77,3 +113,34 @@ func TestQuoteUnquote(t *testing.T) {
 		}
 	}
 }
+
+func TestQuoteUnquoteErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`quote(unquote(fn(x) { x }))`,
+			"ERROR: argument to `unquote` not supported, got FUNCTION",
+		},
+		{
+			`quote(unquote([1, fn(x) { x }]))`,
+			"ERROR: argument to `unquote` not supported, got ARRAY",
+		},
+		{
+			`quote(unquote((1, [fn(x) { x }])))`,
+			"ERROR: argument to `unquote` not supported, got TUPLE",
+		},
+		{
+			`quote(1 + unquote(len(1)))`,
+			"ERROR: argument to `len` not supported, got INTEGER",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package evaluator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/object"
+)
+
+// regexBuiltins defines the builtin functions for regexes
+// each of them takes the regex first, which may be given as a string compiled for the call
+var regexBuiltins = map[string]*object.Builtin{
+	"regex": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			re, err := getRegex(args[0], "regex")
+			if err != nil {
+				return err
+			}
+			return &object.Regex{Value: re}
+		},
+	},
+	// match(re, s) reports whether the regex matches anywhere in the string, and ^ and $ anchor it
+	"match": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 2, "match")
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(re.MatchString(str))
+		},
+	},
+	"find": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 2, "find")
+			if err != nil {
+				return err
+			}
+			loc := re.FindStringIndex(str)
+			if loc == nil {
+				return NULL
+			}
+			return &object.String{Value: str[loc[0]:loc[1]]}
+		},
+	},
+	"findall": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 2, "findall")
+			if err != nil {
+				return err
+			}
+			matches := re.FindAllString(str, -1)
+			elements := make([]object.Object, len(matches))
+			for i, match := range matches {
+				elements[i] = &object.String{Value: match}
+			}
+			return object.NewArray(elements)
+		},
+	},
+	// captures(re, s) gives the groups of the first match, or null if there is no match
+	"captures": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 2, "captures")
+			if err != nil {
+				return err
+			}
+			loc := re.FindStringSubmatchIndex(str)
+			if loc == nil {
+				return NULL
+			}
+			return newCaptures(re, str, loc)
+		},
+	},
+	// replace(re, s, replacement) replaces every match
+	// the replacement is a string, in which $1 and ${name} are the groups, or a function called with the captures of each match
+	"replace": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 3, "replace")
+			if err != nil {
+				return err
+			}
+			switch replacement := args[2].(type) {
+			case *object.String:
+				return &object.String{Value: re.ReplaceAllString(str, replacement.Value)}
+			case *object.Function, *object.Builtin:
+				return replaceWithFunction(re, str, replacement)
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "replacement of `replace` must be STRING or FUNCTION, got %s", args[2].Type())
+			}
+		},
+	},
+	"split": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			re, str, err := getRegexArguments(args, 2, "split")
+			if err != nil {
+				return err
+			}
+			parts := re.Split(str, -1)
+			elements := make([]object.Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &object.String{Value: part}
+			}
+			return object.NewArray(elements)
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range regexBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// getRegex function returns the regex of obj, compiling it if obj is a string
+func getRegex(obj object.Object, name string) (*regexp.Regexp, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Regex:
+		return obj.Value, nil
+	case *object.String:
+		re, err := regexp.Compile(obj.Value)
+		if err != nil {
+			return nil, newError("invalid regex: %s", err)
+		}
+		return re, nil
+	default:
+		return nil, newErrorOfKind(object.TYPE_ERROR, "regex of `%s` must be REGEX or STRING, got %s", name, obj.Type())
+	}
+}
+
+// getRegexArguments function checks the arguments (re, s, ...) of the builtin, and returns the regex and the string
+func getRegexArguments(args []object.Object, want int, name string) (*regexp.Regexp, string, *object.Error) {
+	if len(args) != want {
+		return nil, "", newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=%d", len(args), want)
+	}
+	re, err := getRegex(args[0], name)
+	if err != nil {
+		return nil, "", err
+	}
+	str, ok := args[1].(*object.String)
+	if !ok {
+		return nil, "", newErrorOfKind(object.TYPE_ERROR, "argument to `%s` not supported, got %s", name, args[1].Type())
+	}
+	return re, str.Value, nil
+}
+
+// newCaptures function creates the hash of the groups of a match at loc, as given by FindStringSubmatchIndex
+// the hash maps the numbers of the groups, from 0 for the whole match, and then the names of the named groups to the strings
+// a group which does not take part in the match is null
+func newCaptures(re *regexp.Regexp, str string, loc []int) *object.Hash {
+	groups := make([]object.Object, len(loc)/2)
+	for i := range groups {
+		if loc[2*i] < 0 {
+			groups[i] = NULL
+		} else {
+			groups[i] = &object.String{Value: str[loc[2*i]:loc[2*i+1]]}
+		}
+	}
+	captures := object.NewHash()
+	for i, group := range groups {
+		captures.Set(&object.Integer{Value: int64(i)}, group)
+	}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			captures.Set(&object.String{Value: name}, groups[i])
+		}
+	}
+	return captures
+}
+
+// replaceWithFunction function replaces every match of the regex with the string returned by fn for its captures
+func replaceWithFunction(re *regexp.Regexp, str string, fn object.Object) object.Object {
+	var out strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(str, -1) {
+		replacement := applyFunction(fn, []object.Object{newCaptures(re, str, loc)})
+		if isError(replacement) {
+			return replacement
+		}
+		replaced, ok := replacement.(*object.String)
+		if !ok {
+			return newErrorOfKind(object.TYPE_ERROR, "replacement of `replace` must return STRING, got %s", replacement.Type())
+		}
+		out.WriteString(str[last:loc[0]])
+		out.WriteString(replaced.Value)
+		last = loc[1]
+	}
+	out.WriteString(str[last:])
+	return &object.String{Value: out.String()}
+}
+
+// evalRegexLiteral function
+func evalRegexLiteral(literal *ast.RegexLiteral) object.Object {
+	return &object.Regex{Value: literal.Value}
+}
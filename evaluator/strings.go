@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BOBO1997/monkey/object"
+)
+
+// stringBuiltins defines the builtin functions for strings
+// the other builtins treat strings as sequences of characters (runes), and these give the bytes and the graphemes
+var stringBuiltins = map[string]*object.Builtin{
+	"bytelen": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `bytelen` not supported, got %s", args[0].Type())
+			}
+			return &object.Integer{Value: int64(len(str.Value))}
+		},
+	},
+	"graphemes": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `graphemes` not supported, got %s", args[0].Type())
+			}
+			clusters := []object.Object{}
+			for _, cluster := range graphemeClusters(str.Value) {
+				clusters = append(clusters, &object.String{Value: cluster})
+			}
+			return object.NewArray(clusters)
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range stringBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// evalStringIndexExpression function accesses the index-th character (rune) of the string
+// the string is walked only up to the index, and ASCII bytes are stepped over without decoding
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	s := str.(*object.String).Value
+	idx := index.(*object.Integer).Value
+	if idx < 0 || int64(len(s)) <= idx {
+		return NULL
+	}
+	pos := 0
+	for ; idx > 0 && pos < len(s); idx-- {
+		if s[pos] < utf8.RuneSelf {
+			pos++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[pos:])
+		pos += size
+	}
+	if pos >= len(s) {
+		return NULL
+	}
+	if s[pos] < utf8.RuneSelf {
+		return &object.String{Value: s[pos : pos+1]}
+	}
+	r, _ := utf8.DecodeRuneInString(s[pos:])
+	return &object.String{Value: string(r)}
+}
+
+// graphemeClusters function splits s into user-perceived characters
+/*
+	This follows the extended grapheme cluster rules of Unicode for the common cases:
+	a CR LF pair, a character followed by combining marks, variation selectors, emoji modifiers or tags,
+	emoji joined by ZERO WIDTH JOINER, and pairs of regional indicators (flags).
+	Hangul syllables spelled with conjoining jamo are not joined.
+*/
+func graphemeClusters(s string) []string {
+	runes := []rune(s)
+	clusters := []string{}
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || isGraphemeBoundary(runes[start:i], runes[i]) {
+			clusters = append(clusters, string(runes[start:i]))
+			start = i
+		}
+	}
+	return clusters
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isGraphemeBoundary function reports whether the cluster so far ends before next
+func isGraphemeBoundary(cluster []rune, next rune) bool {
+	prev := cluster[len(cluster)-1]
+	switch {
+	case prev == '\r' && next == '\n':
+		return false
+	case unicode.IsControl(prev) || unicode.IsControl(next):
+		return true
+	case isGraphemeExtend(next) || next == zeroWidthJoiner:
+		return false
+	case prev == zeroWidthJoiner && isPictographic(next):
+		return false
+	case isRegionalIndicator(prev) && isRegionalIndicator(next):
+		count := 0 // the regional indicators are paired from the start of the run
+		for i := len(cluster) - 1; i >= 0 && isRegionalIndicator(cluster[i]); i-- {
+			count++
+		}
+		return count%2 == 0
+	}
+	return true
+}
+
+// isGraphemeExtend function reports whether r extends the preceding character
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(0x1f3fb <= r && r <= 0x1f3ff) || // emoji modifiers (skin tones)
+		(0xe0020 <= r && r <= 0xe007f) // tags
+}
+
+// isPictographic function reports whether r is an emoji or another pictograph
+func isPictographic(r rune) bool {
+	return unicode.Is(unicode.So, r) || (0x1f000 <= r && r <= 0x1faff)
+}
+
+// isRegionalIndicator function reports whether r is one of the letters which make flags in pairs
+func isRegionalIndicator(r rune) bool {
+	return 0x1f1e6 <= r && r <= 0x1f1ff
+}
@@ -0,0 +1,329 @@
+package evaluator
+
+import (
+	"time"
+
+	"github.com/BOBO1997/monkey/object"
+)
+
+// Clock is the clock read by now, which the tests replace with a fixed one
+var Clock = time.Now
+
+// timeLayouts are the layouts known by name to time and format
+// the other layouts are written as Go does, by the reference time 2006-01-02T15:04:05-07:00
+var timeLayouts = map[string]string{
+	"rfc3339":  time.RFC3339Nano,
+	"rfc1123":  time.RFC1123,
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+	"kitchen":  time.Kitchen,
+}
+
+// timeBuiltins defines the builtin functions for times and durations
+var timeBuiltins = map[string]*object.Builtin{
+	"now": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=0", len(args))
+			}
+			return &object.Time{Value: Clock()}
+		},
+	},
+	// time(s, layout = "rfc3339", zone = "UTC") parses the string, and time(n) is the n-th second of the Unix time
+	"time": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 || 3 < len(args) {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1..3", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Time:
+				if len(args) != 1 {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+				}
+				return arg
+			case *object.Integer:
+				if len(args) != 1 {
+					return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+				}
+				return &object.Time{Value: time.Unix(arg.Value, 0).UTC()}
+			case *object.String:
+				layout := time.RFC3339Nano
+				if len(args) >= 2 {
+					var err *object.Error
+					if layout, err = getTimeLayout(args[1], "time"); err != nil {
+						return err
+					}
+				}
+				location := time.UTC
+				if len(args) == 3 {
+					var err *object.Error
+					if location, err = getLocation(args[2], "time"); err != nil {
+						return err
+					}
+				}
+				t, err := time.ParseInLocation(layout, arg.Value, location)
+				if err != nil {
+					return newError("could not parse %q as time: %s", arg.Value, err)
+				}
+				return &object.Time{Value: t}
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `time` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	// duration(s) parses the string such as "1h30m", in the units h, m, s, ms, us and ns
+	"duration": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=1", len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Duration:
+				return arg
+			case *object.String:
+				d, err := time.ParseDuration(arg.Value)
+				if err != nil {
+					return newError("could not parse %q as duration", arg.Value)
+				}
+				return &object.Duration{Value: d}
+			default:
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `duration` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"format": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			t, ok := args[0].(*object.Time)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `format` not supported, got %s", args[0].Type())
+			}
+			layout, err := getTimeLayout(args[1], "format")
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: t.Value.Format(layout)}
+		},
+	},
+	// timezone(t, zone) is the same instant as t, shown in the zone such as "Asia/Tokyo", "UTC" or "Local"
+	"timezone": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newErrorOfKind(object.ARGUMENT_ERROR, "wrong number of arguments, got=%d, want=2", len(args))
+			}
+			t, ok := args[0].(*object.Time)
+			if !ok {
+				return newErrorOfKind(object.TYPE_ERROR, "argument to `timezone` not supported, got %s", args[0].Type())
+			}
+			location, err := getLocation(args[1], "timezone")
+			if err != nil {
+				return err
+			}
+			return &object.Time{Value: t.Value.In(location)}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range timeBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// getTimeLayout function returns the layout named by obj, or obj itself written as Go does
+func getTimeLayout(obj object.Object, name string) (string, *object.Error) {
+	str, ok := obj.(*object.String)
+	if !ok {
+		return "", newErrorOfKind(object.TYPE_ERROR, "layout of `%s` must be STRING, got %s", name, obj.Type())
+	}
+	if layout, ok := timeLayouts[str.Value]; ok {
+		return layout, nil
+	}
+	return str.Value, nil
+}
+
+// getLocation function returns the time zone named by obj
+// the zones are looked up in the zoneinfo of the system, and a zone not found there is an error
+func getLocation(obj object.Object, name string) (*time.Location, *object.Error) {
+	str, ok := obj.(*object.String)
+	if !ok {
+		return nil, newErrorOfKind(object.TYPE_ERROR, "zone of `%s` must be STRING, got %s", name, obj.Type())
+	}
+	location, err := time.LoadLocation(str.Value)
+	if err != nil {
+		return nil, newErrorOfKind(object.ARGUMENT_ERROR, "unknown time zone: %s", str.Value)
+	}
+	return location, nil
+}
+
+// evalTimeInfixExpression function
+// this function is only called when one of left and right is a time or a duration
+/*
+	time + duration, duration + time and time - duration are times,
+	time - time, duration + duration, duration - duration, duration * integer and duration / integer are durations,
+	and duration / duration is an integer
+*/
+func evalTimeInfixExpression(operator string, left, right object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Time:
+		switch right := right.(type) {
+		case *object.Time:
+			switch operator {
+			case "-": // saturated at the limits of durations, about 292 years
+				return &object.Duration{Value: left.Value.Sub(right.Value)}
+			case "<", ">", "<=", ">=":
+				return evalComparison(operator, left, right)
+			}
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.Time{Value: left.Value.Add(right.Value)}
+			case "-":
+				if right.Value == minDuration {
+					return newErrorOfKind(object.OVERFLOW_ERROR, "duration overflow: -(%s)", right.Inspect())
+				}
+				return &object.Time{Value: left.Value.Add(-right.Value)}
+			}
+		}
+	case *object.Duration:
+		switch right := right.(type) {
+		case *object.Time:
+			if operator == "+" {
+				return &object.Time{Value: right.Value.Add(left.Value)}
+			}
+		case *object.Duration:
+			switch operator {
+			case "+", "-":
+				return evalDurationArithmetic(operator, left, right, int64(right.Value))
+			case "/":
+				if right.Value == 0 {
+					return newError("division by zero")
+				}
+				if left.Value == minDuration && right.Value == -1 {
+					return newErrorOfKind(object.OVERFLOW_ERROR, "duration overflow: %s / %s", left.Inspect(), right.Inspect())
+				}
+				return &object.Integer{Value: int64(left.Value / right.Value)}
+			case "<", ">", "<=", ">=":
+				return evalComparison(operator, left, right)
+			}
+		case *object.Integer:
+			switch operator {
+			case "*", "/":
+				return evalDurationArithmetic(operator, left, right, right.Value)
+			}
+		}
+	case *object.Integer:
+		if right, ok := right.(*object.Duration); ok && operator == "*" {
+			return evalDurationArithmetic(operator, right, left, left.Value)
+		}
+	}
+	if left.Type() != right.Type() {
+		return newErrorOfKind(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// isTimeOrDuration function
+func isTimeOrDuration(obj object.Object) bool {
+	return obj.Type() == object.TIME_OBJ || obj.Type() == object.DURATION_OBJ
+}
+
+// minDuration is the least duration, which has no negation
+const minDuration = time.Duration(-1 << 63)
+
+// evalDurationArithmetic function applies the operator to the nanoseconds of the duration and n
+// durations do not promote to big integers, so the overflow is an error whatever OverflowPolicy is
+func evalDurationArithmetic(operator string, d *object.Duration, operand object.Object, n int64) object.Object {
+	value := int64(d.Value)
+	var result int64
+	overflow := false
+	switch operator {
+	case "+":
+		result = value + n
+		overflow = (n > 0 && result < value) || (n < 0 && result > value)
+	case "-":
+		result = value - n
+		overflow = (n > 0 && result > value) || (n < 0 && result < value)
+	case "*":
+		result = value * n
+		overflow = value != 0 && (result/value != n || (value == -1 && n == -1<<63) || (n == -1 && value == -1<<63))
+	case "/":
+		if n == 0 {
+			return newError("division by zero")
+		}
+		if n == -1 && value == -1<<63 {
+			overflow = true
+		}
+		result = value / n
+	}
+	if overflow {
+		return newErrorOfKind(object.OVERFLOW_ERROR, "duration overflow: %s %s %s", d.Inspect(), operator, operand.Inspect())
+	}
+	return &object.Duration{Value: time.Duration(result)}
+}
+
+// evalTimeIndexExpression function accesses the field of the time, such as t.year
+func evalTimeIndexExpression(t, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "field name must be STRING, got %s", index.Type())
+	}
+	value := t.(*object.Time).Value
+	switch name.Value {
+	case "year":
+		return &object.Integer{Value: int64(value.Year())}
+	case "month":
+		return &object.Integer{Value: int64(value.Month())}
+	case "day":
+		return &object.Integer{Value: int64(value.Day())}
+	case "hour":
+		return &object.Integer{Value: int64(value.Hour())}
+	case "minute":
+		return &object.Integer{Value: int64(value.Minute())}
+	case "second":
+		return &object.Integer{Value: int64(value.Second())}
+	case "nanosecond":
+		return &object.Integer{Value: int64(value.Nanosecond())}
+	case "weekday":
+		return &object.String{Value: value.Weekday().String()}
+	case "yearday":
+		return &object.Integer{Value: int64(value.YearDay())}
+	case "zone":
+		zone, _ := value.Zone()
+		return &object.String{Value: zone}
+	case "offset": // in seconds east of UTC
+		_, offset := value.Zone()
+		return &object.Integer{Value: int64(offset)}
+	case "unix":
+		return &object.Integer{Value: value.Unix()}
+	default:
+		return newErrorOfKind(object.NAME_ERROR, "unknown field `%s` of TIME", name.Value)
+	}
+}
+
+// evalDurationIndexExpression function accesses the length of the duration in a unit, such as d.seconds
+// the lengths are truncated toward zero
+func evalDurationIndexExpression(d, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "field name must be STRING, got %s", index.Type())
+	}
+	value := d.(*object.Duration).Value
+	switch name.Value {
+	case "hours":
+		return &object.Integer{Value: int64(value / time.Hour)}
+	case "minutes":
+		return &object.Integer{Value: int64(value / time.Minute)}
+	case "seconds":
+		return &object.Integer{Value: int64(value / time.Second)}
+	case "milliseconds":
+		return &object.Integer{Value: int64(value / time.Millisecond)}
+	case "nanoseconds":
+		return &object.Integer{Value: int64(value)}
+	default:
+		return newErrorOfKind(object.NAME_ERROR, "unknown field `%s` of DURATION", name.Value)
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 // Lexer is a struct holding the information of whole source code and the counter of lexer
 type Lexer struct {
-	input        string // the whole source
+	input        []rune // the whole source, indexed by runes
 	position     int    // the positing currently reading (alrerady read)
 	readPosition int    // the next position to be read
 	ch           rune   // one charactor at the position
@@ -15,7 +15,7 @@ type Lexer struct {
 // New function makes a new *Lexer struct
 // input is a string of raw token
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: []rune(input)}
 	l.readChar()
 	return l
 }
@@ -26,28 +26,44 @@ func (l *Lexer) readChar() {
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // 0 represents EOF
 	} else {
-		l.ch = []rune(l.input)[l.readPosition] // read a new rune
+		l.ch = l.input[l.readPosition] // read a new rune
 	}
 	l.position = l.readPosition
 	l.readPosition++
 }
 
 // readIdentifier method reads forward the source code and return an identifier
+// an identifier starts with a letter, and may contain digits after that, such as base64
 func (l *Lexer) readIdentifier() string {
 	startPos := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[startPos:l.position]
+	return string(l.input[startPos:l.position])
 }
 
 // readNumber method reads forward the source code and return a number
-func (l *Lexer) readNumber() string {
+// a number with the suffix d, such as 12.50d, is a decimal, and a fraction without the suffix is illegal
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	startPos := l.position
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[startPos:l.position]
+	fraction := l.ch == '.' && isDigit(l.peekChar())
+	if fraction {
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+	if l.ch == 'd' && !isLetter(l.peekChar()) && !isDigit(l.peekChar()) {
+		l.readChar()
+		return string(l.input[startPos:l.position]), token.DECIMAL
+	}
+	if fraction {
+		return string(l.input[startPos:l.position]), token.ILLEGAL
+	}
+	return string(l.input[startPos:l.position]), token.INT
 }
 
 // readString method reads forward the source code and return a string
@@ -59,16 +75,46 @@ func (l *Lexer) readString() string {
 			break
 		}
 	}
-	return l.input[startPos:l.position]
+	return string(l.input[startPos:l.position])
+}
+
+// readPrefixedString method reads forward the source code and return a literal with a prefix, such as b"\x00" or r"\d+"
+// the escape sequences are left as they are, except that an escaped quote does not end the literal
+func (l *Lexer) readPrefixedString() string {
+	startPos := l.position
+	l.readChar() // the opening quote
+	for {
+		l.readChar()
+		if l.ch == '\\' {
+			l.readChar() // the escaped character
+		} else if l.ch == '"' {
+			break
+		}
+		if l.ch == 0 {
+			break
+		}
+	}
+	if l.ch == 0 { // unterminated
+		return string(l.input[startPos:l.position])
+	}
+	return string(l.input[startPos : l.position+1])
 }
 
 // peekChar method peeks the next rune, for finding the operator with two rune
 func (l *Lexer) peekChar() rune {
-	if len([]rune(l.input)) <= l.readPosition {
+	if len(l.input) <= l.readPosition {
 		return 0
 	} else {
-		return rune(l.input[l.readPosition])
+		return l.input[l.readPosition]
+	}
+}
+
+// peekSecondChar method peeks the rune after the next rune, for finding the operator with three rune
+func (l *Lexer) peekSecondChar() rune {
+	if len(l.input) <= l.readPosition+1 {
+		return 0
 	}
+	return l.input[l.readPosition+1]
 }
 
 // skipWhitespace method skips the white space and escape sequences
@@ -107,11 +153,28 @@ func (l *Lexer) NextToken() token.Token {
 	case '+':
 		tok = newToken(token.PLUS, l.ch)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch)
 	case '/':
 		tok = newToken(token.SLASH, l.ch)
+	case '|':
+		tok = newToken(token.PIPE, l.ch)
+	case '&':
+		tok = newToken(token.AMPERSAND, l.ch)
+	case '#':
+		if l.peekChar() == '{' {
+			l.readChar()
+			tok = token.Token{Type: token.HASHBRACE, Literal: "#{"}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case '<':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -136,6 +199,41 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.BANG, l.ch)
 		}
+	case '?':
+		switch l.peekChar() {
+		case '.':
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.QDOT, Literal: string(ch) + string(l.ch)}
+		case '[':
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.QLBRACKET, Literal: string(ch) + string(l.ch)}
+		case '?':
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.NULLISH, Literal: string(ch) + string(l.ch)}
+		default:
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '.':
+		if l.peekChar() == '.' {
+			switch l.peekSecondChar() {
+			case '.':
+				l.readChar()
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			case '=':
+				l.readChar()
+				l.readChar()
+				tok = token.Token{Type: token.DOTDOTEQ, Literal: "..="}
+			default:
+				l.readChar()
+				tok = token.Token{Type: token.DOTDOT, Literal: ".."}
+			}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
@@ -151,13 +249,18 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = ""
 		tok.Type = "EOF"
 	default:
-		if isLetter(l.ch) {
+		if l.ch == 'b' && l.peekChar() == '"' {
+			tok.Type = token.BYTES
+			tok.Literal = l.readPrefixedString()
+		} else if l.ch == 'r' && l.peekChar() == '"' {
+			tok.Type = token.REGEX
+			tok.Literal = l.readPrefixedString()
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
+			tok.Literal, tok.Type = l.readNumber()
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
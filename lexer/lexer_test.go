@@ -155,3 +155,242 @@ func TestNextToken2(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenNullSafeOperators(t *testing.T) {
+	input := `null ?? a?.b?[c] ?`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.NULL, "null"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "a"},
+		{token.QDOT, "?."},
+		{token.IDENT, "b"},
+		{token.QLBRACKET, "?["},
+		{token.IDENT, "c"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, "?"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenDots(t *testing.T) {
+	input := `0..5 0..=5 ...xs p.x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0"},
+		{token.DOTDOT, ".."},
+		{token.INT, "5"},
+		{token.INT, "0"},
+		{token.DOTDOTEQ, "..="},
+		{token.INT, "5"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "xs"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenArrow(t *testing.T) {
+	input := `fn(x: int) -> int { x - 1 }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.COLON, ":"},
+		{token.IDENT, "int"},
+		{token.RPAREN, ")"},
+		{token.ARROW, "->"},
+		{token.IDENT, "int"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenDecimals(t *testing.T) {
+	input := `12.50d + 3d; 1.5 1..2 dd`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.DECIMAL, "12.50d"},
+		{token.PLUS, "+"},
+		{token.DECIMAL, "3d"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "1.5"},
+		{token.INT, "1"},
+		{token.DOTDOT, ".."},
+		{token.INT, "2"},
+		{token.IDENT, "dd"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenSets(t *testing.T) {
+	input := `#{1} | a & b #`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.HASHBRACE, "#{"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.PIPE, "|"},
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.ILLEGAL, "#"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenBytes(t *testing.T) {
+	input := `b"a\"b" + bar b base64`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.BYTES, `b"a\"b"`},
+		{token.PLUS, "+"},
+		{token.IDENT, "bar"},
+		{token.IDENT, "b"},
+		{token.IDENT, "base64"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenRegex(t *testing.T) {
+	input := `r"\d+\"" r "a"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.REGEX, `r"\d+\""`},
+		{token.IDENT, "r"},
+		{token.STRING, "a"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenUnicode(t *testing.T) {
+	input := `"héllo" + "日本語"; é`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "héllo"},
+		{token.PLUS, "+"},
+		{token.STRING, "日本語"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "é"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected %q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - tokenliteral wrong. expected %q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
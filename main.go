@@ -2,11 +2,18 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/user"
 
+	"github.com/BOBO1997/monkey/ast"
+	"github.com/BOBO1997/monkey/evaluator"
+	"github.com/BOBO1997/monkey/lexer"
+	"github.com/BOBO1997/monkey/object"
+	"github.com/BOBO1997/monkey/parser"
 	"github.com/BOBO1997/monkey/repl"
+	"github.com/BOBO1997/monkey/types"
 	"github.com/urfave/cli"
 )
 
@@ -20,11 +27,47 @@ func main() {
 	app.Name = "monkey"
 	app.Usage = "monkey"
 	app.Version = "0.0.1"
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "trap-overflow",
+			Usage: "raise an OverflowError instead of promoting integers to big integers",
+		},
+		cli.IntFlag{
+			Name:  "decimal-precision",
+			Value: evaluator.DecimalPrecision,
+			Usage: "number of the digits after the decimal point kept by the division of decimals",
+		},
+		cli.StringFlag{
+			Name:  "decimal-rounding",
+			Value: "half_even",
+			Usage: "rounding mode of the division of decimals: half_even, half_up, half_down, up, down, ceiling or floor",
+		},
+	}
 	app.Action = func(c *cli.Context) error {
+		if c.Bool("trap-overflow") {
+			evaluator.OverflowPolicy = evaluator.TrapOnOverflow
+		}
+		if c.Int("decimal-precision") < 0 {
+			return cli.NewExitError("decimal precision must not be negative", 2)
+		}
+		evaluator.DecimalPrecision = c.Int("decimal-precision")
+		mode, ok := object.RoundingModes[c.String("decimal-rounding")]
+		if !ok {
+			return cli.NewExitError(fmt.Sprintf("unknown rounding mode: %s", c.String("decimal-rounding")), 2)
+		}
+		evaluator.DecimalRounding = mode
 		fmt.Printf("Hello %s! This is the Monkey programming language!\n", user.Username)
 		repl.Start(os.Stdin, os.Stdout)
 		return nil
 	}
+	app.Commands = []cli.Command{
+		{
+			Name:      "check",
+			Usage:     "report the type errors of the files without running them",
+			ArgsUsage: "<file>...",
+			Action:    check,
+		},
+	}
 
 	err = app.Run(os.Args)
 	if err != nil {
@@ -32,4 +75,33 @@ func main() {
 	}
 }
 
-// total 2284 lines
+// check function type-checks each file given as an argument
+// macros are expanded before checking, since the checker sees only the expanded program
+func check(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("no file to check", 2)
+	}
+	failed := false
+	for _, filename := range c.Args() {
+		source, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return cli.NewExitError(err, 2)
+		}
+		p := parser.New(lexer.New(string(source)))
+		program := p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			macroEnv := object.NewEnvironment()
+			evaluator.DefineMacros(program, macroEnv)
+			errors = types.Check(evaluator.ExpandMacros(program, macroEnv).(*ast.Program))
+		}
+		for _, msg := range errors {
+			fmt.Printf("%s: %s\n", filename, msg)
+		}
+		failed = failed || len(errors) > 0
+	}
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Bytes struct is an immutable sequence of bytes, for binary data
+type Bytes struct {
+	Value []byte
+}
+
+// Type method of Bytes struct
+func (b *Bytes) Type() ObjectType {
+	return BYTES_OBJ
+}
+
+// Inspect method of Bytes struct returns the bytes as a literal, such as b"ab\x00"
+// the printable ASCII characters are kept, and the other bytes are escaped
+func (b *Bytes) Inspect() string {
+	var out strings.Builder
+	out.WriteString(`b"`)
+	for _, c := range b.Value {
+		switch {
+		case c == '"' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case ' ' <= c && c <= '~':
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, `\x%02x`, c)
+		}
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
+
+// HashKey method of Bytes struct
+func (b *Bytes) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write(b.Value)
+	return HashKey{
+		Type:  b.Type(),
+		Value: h.Sum64(),
+	}
+}
+
+// Iterator method of Bytes struct iterates over the bytes as integers
+func (b *Bytes) Iterator() Iterator {
+	elements := make([]Object, len(b.Value))
+	for i, c := range b.Value {
+		elements[i] = &Integer{Value: int64(c)}
+	}
+	return &sliceIterator{elements: elements}
+}
@@ -0,0 +1,241 @@
+package object
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// Equal function reports whether the two objects are structurally equal
+/*
+	Integers, decimals, booleans, strings, bytes, times and durations are equal by value, regexes by pattern, arrays, tuples, hashes, sets, ranges, records and enum values by their contents.
+	A decimal is equal to the integer of the same value, as their hash keys are the same.
+	The other objects, such as functions, are equal only to themselves.
+	Objects which contain themselves are compared without looping: a pair already being compared is assumed to be equal.
+*/
+func Equal(left, right Object) bool {
+	return equal(left, right, nil)
+}
+
+func equal(left, right Object, visiting map[[2]Object]bool) bool {
+	if left == right {
+		return true
+	}
+	if left.Type() == DECIMAL_OBJ || right.Type() == DECIMAL_OBJ {
+		order, ok := Compare(left, right)
+		return ok && order == 0
+	}
+	if left.Type() != right.Type() {
+		return false
+	}
+
+	switch left := left.(type) {
+	case *Integer:
+		return left.Value == right.(*Integer).Value
+	case *BigInt:
+		return left.Value.Cmp(right.(*BigInt).Value) == 0
+	case *Boolean:
+		return left.Value == right.(*Boolean).Value
+	case *String:
+		return left.Value == right.(*String).Value
+	case *Bytes:
+		return bytes.Equal(left.Value, right.(*Bytes).Value)
+	case *Time:
+		return left.Value.Equal(right.(*Time).Value)
+	case *Duration:
+		return left.Value == right.(*Duration).Value
+	case *Regex:
+		return left.Value.String() == right.(*Regex).Value.String()
+	case *Range:
+		right := right.(*Range)
+		if left.Empty() || right.Empty() {
+			return left.Empty() && right.Empty()
+		}
+		return left.Start == right.Start && left.Last() == right.Last()
+	case *Array, *Tuple, *Set, *Hash, *Record, *EnumValue:
+		return equalContainers(left, right, visiting)
+	default:
+		return false
+	}
+}
+
+// equalContainers function compares the objects which may contain themselves
+// visiting holds the pairs being compared, and is allocated by the outermost container, so that comparing scalars allocates nothing
+func equalContainers(left, right Object, visiting map[[2]Object]bool) bool {
+	if visiting == nil {
+		visiting = make(map[[2]Object]bool)
+	}
+	pair := [2]Object{left, right}
+	if visiting[pair] {
+		return true
+	}
+	visiting[pair] = true
+	defer delete(visiting, pair)
+
+	switch left := left.(type) {
+	case *Array:
+		return equalElements(left.Elements(), right.(*Array).Elements(), visiting)
+	case *Tuple:
+		return equalElements(left.Elements, right.(*Tuple).Elements, visiting)
+	case *Set:
+		right := right.(*Set)
+		if left.Len() != right.Len() {
+			return false
+		}
+		for _, element := range left.Sorted() {
+			if !right.Has(element) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		right := right.(*Hash)
+		if left.Len() != right.Len() {
+			return false
+		}
+		for _, pair := range left.Pairs() {
+			other, ok := right.Get(pair.Key)
+			if !ok || !equal(pair.Value, other, visiting) {
+				return false
+			}
+		}
+		return true
+	case *Record:
+		right := right.(*Record)
+		return left.Struct == right.Struct && equalElements(left.Values, right.Values, visiting)
+	case *EnumValue:
+		right := right.(*EnumValue)
+		return left.Variant == right.Variant && equalElements(left.Values, right.Values, visiting)
+	default:
+		return false
+	}
+}
+
+func equalElements(left, right []Object, visiting map[[2]Object]bool) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if !equal(left[i], right[i], visiting) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare function orders the two objects, and returns a negative number, zero or a positive number
+// if left is less than, equal to or greater than right
+/*
+	Integers (and big integers), decimals and durations are ordered by value, times chronologically, strings and bytes lexicographically by bytes,
+	and arrays and tuples lexicographically by their elements, where a prefix is less than the longer one.
+	The second result is false if the objects are not ordered, such as objects of different types.
+*/
+func Compare(left, right Object) (int, bool) {
+	switch left := left.(type) {
+	case *Integer, *BigInt:
+		if left, ok := left.(*Integer); ok {
+			if right, ok := right.(*Integer); ok {
+				switch {
+				case left.Value < right.Value:
+					return -1, true
+				case left.Value > right.Value:
+					return 1, true
+				}
+				return 0, true
+			}
+		}
+		if right, ok := right.(*Decimal); ok {
+			leftVal, _ := ToDecimal(left)
+			return leftVal.Cmp(right), true
+		}
+		leftVal, _ := ToBigInt(left)
+		rightVal, ok := ToBigInt(right)
+		if !ok {
+			return 0, false
+		}
+		return leftVal.Cmp(rightVal), true
+	case *Decimal:
+		rightVal, ok := ToDecimal(right)
+		if !ok {
+			return 0, false
+		}
+		return left.Cmp(rightVal), true
+	case *Bytes:
+		right, ok := right.(*Bytes)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(left.Value, right.Value), true
+	case *Time:
+		right, ok := right.(*Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case left.Value.Before(right.Value):
+			return -1, true
+		case left.Value.After(right.Value):
+			return 1, true
+		}
+		return 0, true
+	case *Duration:
+		right, ok := right.(*Duration)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case left.Value < right.Value:
+			return -1, true
+		case left.Value > right.Value:
+			return 1, true
+		}
+		return 0, true
+	case *String:
+		right, ok := right.(*String)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case left.Value < right.Value:
+			return -1, true
+		case left.Value > right.Value:
+			return 1, true
+		}
+		return 0, true
+	case *Array:
+		right, ok := right.(*Array)
+		if !ok {
+			return 0, false
+		}
+		return compareElements(left.Elements(), right.Elements())
+	case *Tuple:
+		right, ok := right.(*Tuple)
+		if !ok {
+			return 0, false
+		}
+		return compareElements(left.Elements, right.Elements)
+	default:
+		return 0, false
+	}
+}
+
+func compareElements(left, right []Object) (int, bool) {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		order, ok := Compare(left[i], right[i])
+		if !ok || order != 0 {
+			return order, ok
+		}
+	}
+	return len(left) - len(right), true
+}
+
+// ToBigInt function returns the value of an Integer or a BigInt as *big.Int, which must not be modified
+func ToBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInt:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,157 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestEqualWithCycles(t *testing.T) {
+	a := &Array{}
+	a.elements = NewVector([]Object{&Integer{Value: 1}, a})
+	b := &Array{}
+	b.elements = NewVector([]Object{&Integer{Value: 1}, b})
+	c := &Array{}
+	c.elements = NewVector([]Object{&Integer{Value: 2}, c})
+
+	if !Equal(a, b) {
+		t.Errorf("cyclic arrays with the same elements are not equal")
+	}
+	if Equal(a, c) {
+		t.Errorf("cyclic arrays with different elements are equal")
+	}
+
+	key := &String{Value: "self"}
+	h := NewHash()
+	h.Set(key, h)
+	g := NewHash()
+	g.Set(key, g)
+
+	if !Equal(h, g) {
+		t.Errorf("cyclic hashes with the same pairs are not equal")
+	}
+}
+
+func TestEqualScalarsDoNotAllocate(t *testing.T) {
+	left, right := &Integer{Value: 1}, &Integer{Value: 1}
+	str := &String{Value: "a"}
+	allocs := testing.AllocsPerRun(100, func() {
+		Equal(left, right)
+		Equal(left, str)
+	})
+	if allocs != 0 {
+		t.Errorf("Equal of scalars allocates. got=%v", allocs)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		left     Object
+		right    Object
+		expected int
+		ok       bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 2}, -1, true},
+		{&String{Value: "b"}, &String{Value: "a"}, 1, true},
+		{NewArray([]Object{&Integer{Value: 1}}), NewArray([]Object{&Integer{Value: 1}}), 0, true},
+		{NewArray([]Object{}), NewArray([]Object{&Integer{Value: 1}}), -1, true},
+		{&Integer{Value: 1}, &String{Value: "1"}, 0, false},
+		{&Boolean{Value: true}, &Boolean{Value: false}, 0, false},
+		{&Decimal{Value: big.NewInt(150), Scale: 2}, &Decimal{Value: big.NewInt(15), Scale: 1}, 0, true},
+		{&Decimal{Value: big.NewInt(15), Scale: 1}, &Integer{Value: 2}, -1, true},
+		{&Integer{Value: 2}, &Decimal{Value: big.NewInt(15), Scale: 1}, 1, true},
+		{&Decimal{Value: big.NewInt(15), Scale: 1}, &String{Value: "1.5"}, 0, false},
+	}
+
+	for i, tt := range tests {
+		order, ok := Compare(tt.left, tt.right)
+		if ok != tt.ok {
+			t.Errorf("tests[%d] - ok wrong. want=%t, got=%t", i, tt.ok, ok)
+			continue
+		}
+		if sign(order) != tt.expected {
+			t.Errorf("tests[%d] - order wrong. want=%d, got=%d", i, tt.expected, order)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestDecimalHashKey(t *testing.T) {
+	scaled := &Decimal{Value: big.NewInt(2500), Scale: 3}
+	short := &Decimal{Value: big.NewInt(25), Scale: 1}
+	integral := &Decimal{Value: big.NewInt(300), Scale: 2}
+
+	if scaled.HashKey() != short.HashKey() {
+		t.Errorf("decimals with the same value have different hash keys")
+	}
+	if integral.HashKey() != (&Integer{Value: 3}).HashKey() {
+		t.Errorf("integral decimal has a different hash key from the integer")
+	}
+	if !Equal(integral, &Integer{Value: 3}) {
+		t.Errorf("integral decimal is not equal to the integer")
+	}
+}
+
+// collidingKey type is a key whose hash key is the same for every value
+type collidingKey struct {
+	name string
+}
+
+func (k *collidingKey) Type() ObjectType { return STRING_OBJ }
+func (k *collidingKey) Inspect() string  { return k.name }
+func (k *collidingKey) HashKey() HashKey { return HashKey{Type: STRING_OBJ, Value: 42} }
+
+func TestHashKeyCollisions(t *testing.T) {
+	a := &collidingKey{name: "a"}
+	b := &collidingKey{name: "b"}
+
+	h := NewHash()
+	h.Set(a, &Integer{Value: 1})
+	h.Set(b, &Integer{Value: 2})
+	h.Set(a, &Integer{Value: 3})
+	if h.Len() != 2 {
+		t.Fatalf("colliding keys overwrite each other. got=%d pairs", h.Len())
+	}
+	if value, ok := h.Get(a); !ok || value.Inspect() != "3" {
+		t.Errorf("wrong value of a. got=%v", value)
+	}
+	if value, ok := h.Get(b); !ok || value.Inspect() != "2" {
+		t.Errorf("wrong value of b. got=%v", value)
+	}
+
+	s := NewSet()
+	s.Add(a)
+	s.Add(b)
+	s.Add(a)
+	if s.Len() != 2 || !s.Has(a) || !s.Has(b) || s.Has(&collidingKey{name: "c"}) {
+		t.Errorf("wrong set of colliding elements. got=%s", s.Inspect())
+	}
+}
+
+func TestCompositeHashKey(t *testing.T) {
+	pair := NewArray([]Object{&Integer{Value: 1}, &String{Value: "a"}})
+	same := NewArray([]Object{&Integer{Value: 1}, &String{Value: "a"}})
+	tuple := &Tuple{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	nested := NewArray([]Object{NewHash()})
+
+	if pair.HashKey() != same.HashKey() {
+		t.Errorf("arrays with the same elements have different hash keys")
+	}
+	if pair.HashKey() == tuple.HashKey() {
+		t.Errorf("array and tuple with the same elements have the same hash key")
+	}
+	if _, ok := HashKeyOf(nested); ok {
+		t.Errorf("array of a hash is usable as a hash key")
+	}
+	if _, ok := HashKeyOf(&Null{}); !ok {
+		t.Errorf("null is unusable as a hash key")
+	}
+}
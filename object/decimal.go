@@ -0,0 +1,239 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strings"
+)
+
+// Decimal struct is an exact decimal number, whose value is Value * 10^-Scale
+/*
+	Addition, subtraction and multiplication are exact, and only the division and Round lose digits,
+	as the RoundingMode decides.
+	The scale is kept in the results, so that 12.50d + 1 is 13.50, as written in money.
+*/
+type Decimal struct {
+	Value *big.Int // unscaled value
+	Scale int      // number of the digits after the decimal point, not negative
+}
+
+// Inspect method of Decimal struct
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Value).String()
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	sign := ""
+	if d.Value.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Type method of Decimal struct
+func (d *Decimal) Type() ObjectType {
+	return DECIMAL_OBJ
+}
+
+// HashKey method of Decimal struct
+// decimals with the same value share the key regardless of the scale, and integral decimals share the key with the integers
+func (d *Decimal) HashKey() HashKey {
+	normalized := d.normalize()
+	if normalized.Scale == 0 {
+		if normalized.Value.IsInt64() {
+			return (&Integer{Value: normalized.Value.Int64()}).HashKey()
+		}
+		return (&BigInt{Value: normalized.Value}).HashKey()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(normalized.Inspect()))
+	return HashKey{
+		Type:  d.Type(),
+		Value: h.Sum64(),
+	}
+}
+
+// normalize method of Decimal struct returns the decimal without the trailing zeros after the decimal point
+func (d *Decimal) normalize() *Decimal {
+	return d.trim(0)
+}
+
+// trim method of Decimal struct removes the trailing zeros after the decimal point, keeping at least minScale digits
+func (d *Decimal) trim(minScale int) *Decimal {
+	value := new(big.Int).Set(d.Value)
+	scale := d.Scale
+	ten := big.NewInt(10)
+	remainder := new(big.Int)
+	for scale > minScale {
+		quotient, _ := new(big.Int).QuoRem(value, ten, remainder)
+		if remainder.Sign() != 0 {
+			break
+		}
+		value = quotient
+		scale--
+	}
+	return &Decimal{Value: value, Scale: scale}
+}
+
+// rescale method of Decimal struct returns the unscaled value of the decimal with the larger scale
+func (d *Decimal) rescale(scale int) *big.Int {
+	return new(big.Int).Mul(d.Value, pow10(scale-d.Scale))
+}
+
+// Cmp method of Decimal struct compares the values of the decimals, as big.Int.Cmp does
+func (d *Decimal) Cmp(other *Decimal) int {
+	scale := maxScale(d, other)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Add method of Decimal struct
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	scale := maxScale(d, other)
+	return &Decimal{Value: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), Scale: scale}
+}
+
+// Sub method of Decimal struct
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	scale := maxScale(d, other)
+	return &Decimal{Value: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), Scale: scale}
+}
+
+// Mul method of Decimal struct
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return &Decimal{Value: new(big.Int).Mul(d.Value, other.Value), Scale: d.Scale + other.Scale}
+}
+
+// Neg method of Decimal struct
+func (d *Decimal) Neg() *Decimal {
+	return &Decimal{Value: new(big.Int).Neg(d.Value), Scale: d.Scale}
+}
+
+// Quo method of Decimal struct divides the decimal by other, rounding the quotient to precision digits after the decimal point
+// the trailing zeros of the quotient are removed down to the scale of the operands, so that 10.00d / 4 is 2.50
+// other must not be zero
+func (d *Decimal) Quo(other *Decimal, precision int, mode RoundingMode) *Decimal {
+	scale := maxScale(d, other)
+	if precision > scale {
+		scale = precision
+	}
+	// d / other = (d.Value * 10^other.Scale) / (other.Value * 10^d.Scale), scaled by 10^scale
+	numerator := new(big.Int).Mul(d.Value, pow10(other.Scale+scale))
+	denominator := new(big.Int).Mul(other.Value, pow10(d.Scale))
+	quotient := &Decimal{Value: roundQuo(numerator, denominator, mode), Scale: scale}
+	return quotient.trim(maxScale(d, other))
+}
+
+// Round method of Decimal struct rounds the decimal to scale digits after the decimal point
+// the digits are padded with zeros if the decimal has fewer digits
+func (d *Decimal) Round(scale int, mode RoundingMode) *Decimal {
+	if scale >= d.Scale {
+		return &Decimal{Value: d.rescale(scale), Scale: scale}
+	}
+	return &Decimal{Value: roundQuo(d.Value, pow10(d.Scale-scale), mode), Scale: scale}
+}
+
+// RoundingMode type decides how the digits which do not fit are dropped
+type RoundingMode int
+
+// rounding modes
+const (
+	RoundHalfEven RoundingMode = iota // to the nearest, and ties to the even digit (banker's rounding)
+	RoundHalfUp                       // to the nearest, and ties away from zero
+	RoundHalfDown                     // to the nearest, and ties toward zero
+	RoundUp                           // away from zero
+	RoundDown                         // toward zero (truncation)
+	RoundCeiling                      // toward positive infinity
+	RoundFloor                        // toward negative infinity
+)
+
+// RoundingModes holds the rounding modes by the names used in the source code
+var RoundingModes = map[string]RoundingMode{
+	"half_even": RoundHalfEven,
+	"half_up":   RoundHalfUp,
+	"half_down": RoundHalfDown,
+	"up":        RoundUp,
+	"down":      RoundDown,
+	"ceiling":   RoundCeiling,
+	"floor":     RoundFloor,
+}
+
+// roundQuo function divides numerator by denominator, rounding the quotient to an integer
+func roundQuo(numerator, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int)) // truncated toward zero
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	negative := (numerator.Sign() < 0) != (denominator.Sign() < 0)
+	// half compares the remainder with the half of the denominator
+	half := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(new(big.Int).Abs(denominator))
+
+	var away bool // whether the quotient is rounded away from zero
+	switch mode {
+	case RoundHalfEven:
+		away = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundHalfDown:
+		away = half > 0
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	case RoundCeiling:
+		away = !negative
+	case RoundFloor:
+		away = negative
+	}
+	if !away {
+		return quotient
+	}
+	if negative {
+		return quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient.Add(quotient, big.NewInt(1))
+}
+
+// ParseDecimal function parses a decimal written as "12.50" or "-3", without the suffix of the literal
+func ParseDecimal(s string) (*Decimal, error) {
+	digits := s
+	scale := 0
+	if point := strings.IndexByte(s, '.'); point >= 0 {
+		digits = s[:point] + s[point+1:]
+		scale = len(s) - point - 1
+	}
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.HasPrefix(s, ".") || strings.HasSuffix(s, ".") {
+		return nil, fmt.Errorf("could not parse %q as decimal", s)
+	}
+	return &Decimal{Value: value, Scale: scale}, nil
+}
+
+// ToDecimal function returns an Integer, a BigInt or a Decimal as *Decimal
+func ToDecimal(obj Object) (*Decimal, bool) {
+	if d, ok := obj.(*Decimal); ok {
+		return d, true
+	}
+	value, ok := ToBigInt(obj)
+	if !ok {
+		return nil, false
+	}
+	return &Decimal{Value: value, Scale: 0}, true
+}
+
+// maxScale function returns the larger scale of the decimals
+func maxScale(x, y *Decimal) int {
+	if x.Scale > y.Scale {
+		return x.Scale
+	}
+	return y.Scale
+}
+
+// pow10 function returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
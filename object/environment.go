@@ -24,10 +24,44 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// NewFunctionEnvironment function creates new environment for a function call
+/*
+	The new environment holds a Frame, which is shared by every environment enclosed in it
+*/
+func NewFunctionEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.frame = &Frame{}
+	return env
+}
+
 // Environment struct
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+	frame *Frame // set only for the environment of a function call
+}
+
+// DeferredCall struct holds a function call postponed by a defer statement
+type DeferredCall struct {
+	Fn   Object
+	Args []Object
+}
+
+// Frame struct holds the state of one function call
+type Frame struct {
+	Deferred []*DeferredCall   // evaluated in LIFO order when the call returns
+	Yield    func(Object) bool // set only for the call of a generator function, suspends the call until resumed, or returns false if closed
+}
+
+// Frame method of Environment struct returns the frame of the innermost function call, or nil at the top level
+func (e *Environment) Frame() *Frame {
+	if e.frame != nil {
+		return e.frame
+	}
+	if e.outer != nil {
+		return e.outer.Frame()
+	}
+	return nil
 }
 
 // Get method of Environment struct
@@ -0,0 +1,93 @@
+package object
+
+import "math/bits"
+
+// hamtNode struct is a node of a persistent hash array mapped trie from hash keys to indices
+/*
+	Each level takes the next 5 bits of the hash key value, and only the present entries are stored, in the order of the bitmap.
+	Assoc copies only the path to the changed entry, and shares the rest with the original trie, which is never changed.
+	The hash keys which differ only in their types, and so agree in all the bits, are kept in a list at the bottom.
+*/
+type hamtNode struct {
+	bitmap  uint32
+	entries []hamtEntry
+}
+
+// hamtEntry struct is either a sub-trie, or a hash key with its indices
+type hamtEntry struct {
+	node    *hamtNode
+	key     HashKey
+	indices []int
+}
+
+const (
+	hamtBits = 5
+	hamtMask = 1<<hamtBits - 1
+	hamtMax  = 64 // the number of the bits of a hash key value
+)
+
+// position method of hamtNode struct returns the index of the entry of the bit
+func (n *hamtNode) position(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// get method of hamtNode struct returns the indices of the key
+func (n *hamtNode) get(key HashKey, shift uint) ([]int, bool) {
+	if shift >= hamtMax {
+		for _, entry := range n.entries {
+			if entry.key == key {
+				return entry.indices, true
+			}
+		}
+		return nil, false
+	}
+	bit := uint32(1) << ((key.Value >> shift) & hamtMask)
+	if n.bitmap&bit == 0 {
+		return nil, false
+	}
+	entry := n.entries[n.position(bit)]
+	if entry.node != nil {
+		return entry.node.get(key, shift+hamtBits)
+	}
+	if entry.key == key {
+		return entry.indices, true
+	}
+	return nil, false
+}
+
+// assoc method of hamtNode struct returns a trie with the indices of the key put or replaced
+func (n *hamtNode) assoc(key HashKey, indices []int, shift uint) *hamtNode {
+	if shift >= hamtMax {
+		entries := make([]hamtEntry, len(n.entries), len(n.entries)+1)
+		copy(entries, n.entries)
+		for i := range entries {
+			if entries[i].key == key {
+				entries[i].indices = indices
+				return &hamtNode{entries: entries}
+			}
+		}
+		return &hamtNode{entries: append(entries, hamtEntry{key: key, indices: indices})}
+	}
+	bit := uint32(1) << ((key.Value >> shift) & hamtMask)
+	pos := n.position(bit)
+	if n.bitmap&bit == 0 {
+		entries := make([]hamtEntry, len(n.entries)+1)
+		copy(entries, n.entries[:pos])
+		entries[pos] = hamtEntry{key: key, indices: indices}
+		copy(entries[pos+1:], n.entries[pos:])
+		return &hamtNode{bitmap: n.bitmap | bit, entries: entries}
+	}
+	entries := make([]hamtEntry, len(n.entries))
+	copy(entries, n.entries)
+	entry := n.entries[pos]
+	switch {
+	case entry.node != nil:
+		entries[pos] = hamtEntry{node: entry.node.assoc(key, indices, shift+hamtBits)}
+	case entry.key == key:
+		entries[pos].indices = indices
+	default: // two keys share the bits so far, so they are split in a sub-trie
+		node := (&hamtNode{}).assoc(entry.key, entry.indices, shift+hamtBits)
+		entries[pos] = hamtEntry{node: node.assoc(key, indices, shift+hamtBits)}
+	}
+	return &hamtNode{bitmap: n.bitmap, entries: entries}
+}
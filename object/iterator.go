@@ -0,0 +1,161 @@
+package object
+
+// Iterator interface requires Next method
+/*
+	Next returns the next element and true, or false when the iterator is exhausted.
+	An *Error element reports a failure while producing the elements, and ends the iteration.
+*/
+type Iterator interface {
+	Next() (Object, bool)
+}
+
+// Closer interface is implemented by the iterators holding resources until they are exhausted, such as generators
+// Close releases them early, and returns an *Error if releasing them fails, or nil
+type Closer interface {
+	Close() Object
+}
+
+// Iterable interface requires Iterator method, which returns a new iterator over the elements
+type Iterable interface {
+	Iterator() Iterator
+}
+
+// Iterator method of Array struct
+func (arr *Array) Iterator() Iterator {
+	return &sliceIterator{elements: arr.Elements()}
+}
+
+// Iterator method of Tuple struct
+func (t *Tuple) Iterator() Iterator {
+	return &sliceIterator{elements: t.Elements}
+}
+
+// Iterator method of Range struct
+func (r *Range) Iterator() Iterator {
+	return &rangeIterator{rng: r}
+}
+
+// Iterator method of String struct iterates over the characters (runes) of the string
+func (s *String) Iterator() Iterator {
+	elements := []Object{}
+	for _, ch := range s.Value {
+		elements = append(elements, &String{Value: string(ch)})
+	}
+	return &sliceIterator{elements: elements}
+}
+
+// Iterator method of Hash struct iterates over [key, value] pairs in insertion order
+func (h *Hash) Iterator() Iterator {
+	elements := []Object{}
+	for _, pair := range h.Pairs() {
+		elements = append(elements, NewArray([]Object{pair.Key, pair.Value}))
+	}
+	return &sliceIterator{elements: elements}
+}
+
+// sliceIterator struct iterates over a slice of objects
+type sliceIterator struct {
+	elements []Object
+	position int
+}
+
+// Next method of sliceIterator struct
+func (it *sliceIterator) Next() (Object, bool) {
+	if len(it.elements) <= it.position {
+		return nil, false
+	}
+	element := it.elements[it.position]
+	it.position++
+	return element, true
+}
+
+// rangeIterator struct iterates over a range without materialising it
+type rangeIterator struct {
+	rng      *Range
+	position int64
+}
+
+// Next method of rangeIterator struct
+func (it *rangeIterator) Next() (Object, bool) {
+	if !it.rng.Contains(it.position) {
+		return nil, false
+	}
+	element := &Integer{Value: it.rng.At(it.position)}
+	it.position++
+	return element, true
+}
+
+// Generator struct is created by calling a function containing yield
+/*
+	Source resumes the body of the function until the next yield.
+	A generator is an iterator and an iterable at the same time, so it can be consumed only once.
+	Closing a generator which is not exhausted unwinds its body, so that its defers and finally blocks run.
+*/
+type Generator struct {
+	Name   string // name of the generator function, empty if anonymous
+	Source Iterator
+}
+
+// Inspect method of Generator struct
+func (g *Generator) Inspect() string {
+	if g.Name != "" {
+		return "generator " + g.Name
+	}
+	return "generator"
+}
+
+// Type method of Generator struct
+func (g *Generator) Type() ObjectType {
+	return GENERATOR_OBJ
+}
+
+// Next method of Generator struct
+func (g *Generator) Next() (Object, bool) {
+	return g.Source.Next()
+}
+
+// Close method of Generator struct
+func (g *Generator) Close() Object {
+	if closer, ok := g.Source.(Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Iterator method of Generator struct
+func (g *Generator) Iterator() Iterator {
+	return g
+}
+
+// Iter struct wraps an iterator, for iter() and the lazy results of the iteration builtins
+type Iter struct {
+	Source Iterator
+}
+
+// Inspect method of Iter struct
+func (it *Iter) Inspect() string {
+	return "iterator"
+}
+
+// Type method of Iter struct
+func (it *Iter) Type() ObjectType {
+	return ITERATOR_OBJ
+}
+
+// Next method of Iter struct
+func (it *Iter) Next() (Object, bool) {
+	return it.Source.Next()
+}
+
+// Close method of Iter struct
+func (it *Iter) Close() Object {
+	if closer, ok := it.Source.(Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Iterator method of Iter struct
+func (it *Iter) Iterator() Iterator {
+	return it
+}
@@ -2,8 +2,11 @@ package object
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
 	"strings"
 
 	"github.com/BOBO1997/monkey/ast"
@@ -15,15 +18,31 @@ type ObjectType string
 // object name
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIGINT_OBJ       = "BIGINT"
+	DECIMAL_OBJ      = "DECIMAL"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
+	BYTES_OBJ        = "BYTES"
+	TIME_OBJ         = "TIME"
+	DURATION_OBJ     = "DURATION"
+	REGEX_OBJ        = "REGEX"
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
+	TUPLE_OBJ        = "TUPLE"
+	RANGE_OBJ        = "RANGE"
+	GENERATOR_OBJ    = "GENERATOR"
+	ITERATOR_OBJ     = "ITERATOR"
 	HASH_OBJ         = "HASH"
+	SET_OBJ          = "SET"
+	STRUCT_OBJ       = "STRUCT"
+	RECORD_OBJ       = "RECORD"
+	ENUM_OBJ         = "ENUM"
+	VARIANT_OBJ      = "VARIANT"
+	ENUM_VALUE_OBJ   = "ENUM_VALUE"
 	QUOTE_OBJ        = "QUOTE"
 	MACRO_OBJ        = "MACRO"
 )
@@ -53,6 +72,25 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+// BigInt struct is an integer which does not fit in int64
+/*
+	The evaluator promotes an Integer to a BigInt on overflow, and demotes a BigInt back when it fits in int64,
+	so that every integer has exactly one representation.
+*/
+type BigInt struct {
+	Value *big.Int
+}
+
+// Inspect method of BigInt struct
+func (bi *BigInt) Inspect() string {
+	return bi.Value.String()
+}
+
+// Type method of BigInt struct
+func (bi *BigInt) Type() ObjectType {
+	return BIGINT_OBJ
+}
+
 // boolean
 
 // Boolean struct
@@ -117,9 +155,28 @@ func (rv *ReturnValue) Type() ObjectType {
 	return RETURN_VALUE_OBJ
 }
 
+// error kinds
+const (
+	RUNTIME_ERROR  = "RuntimeError"  // default kind of errors raised by the evaluator
+	TYPE_ERROR     = "TypeError"     // operands or arguments of an unsupported type
+	ARGUMENT_ERROR = "ArgumentError" // wrong number of arguments
+	NAME_ERROR     = "NameError"     // unknown identifier
+	THROWN_ERROR   = "ThrownError"   // value thrown by a throw statement
+	STOP_ITERATION = "StopIteration" // next() called on an exhausted iterator
+	OVERFLOW_ERROR = "OverflowError" // integer overflow when the evaluator traps on overflow
+)
+
 // Error struct
+/*
+	Error is propagated up to the nearest try expression (or to the top level)
+	Value holds the thrown object for errors made by a throw statement, and is nil otherwise
+	Stack holds the names of the functions which the error went through, innermost first
+*/
 type Error struct {
 	Message string
+	Kind    string
+	Value   Object
+	Stack   []string
 }
 
 // Inspect method of Error struct
@@ -134,9 +191,11 @@ func (err *Error) Type() ObjectType {
 
 // Function struct
 type Function struct {
-	Parameters []*ast.Identifier   // ast, not object
-	Body       *ast.BlockStatement // ast, not object
-	Env        *Environment
+	Name        string              // empty if anonymous
+	Parameters  []*ast.Identifier   // ast, not object
+	Body        *ast.BlockStatement // ast, not object
+	Env         *Environment
+	IsGenerator bool // calling the function creates a Generator
 }
 
 // Inspect method of Function struct
@@ -146,7 +205,11 @@ func (f *Function) Inspect() string {
 	for _, p := range f.Parameters {
 		params = append(params, p.Value)
 	}
-	out.WriteString("fn(")
+	out.WriteString("fn")
+	if f.Name != "" {
+		out.WriteString(" " + f.Name)
+	}
+	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") {\n")
 	out.WriteString(f.Body.String())
@@ -180,15 +243,57 @@ func (b *Builtin) Type() ObjectType {
 }
 
 // Array struct
+/*
+	The elements are kept in a persistent vector, so that Push and Set make new arrays in O(log n) sharing the elements.
+	The zero value is an empty array.
+*/
 type Array struct {
-	Elements []Object
+	elements *Vector
+}
+
+// NewArray function creates an array of the elements
+func NewArray(elements []Object) *Array {
+	return &Array{elements: NewVector(elements)}
+}
+
+// vector method of Array struct
+func (arr *Array) vector() *Vector {
+	if arr.elements == nil {
+		return emptyVector
+	}
+	return arr.elements
+}
+
+// Len method of Array struct returns the number of the elements
+func (arr *Array) Len() int {
+	return arr.vector().Len()
+}
+
+// At method of Array struct returns the element at the index, which must be in range
+func (arr *Array) At(i int) Object {
+	return arr.vector().At(i)
+}
+
+// Elements method of Array struct returns the elements in a new slice
+func (arr *Array) Elements() []Object {
+	return arr.vector().Elements()
+}
+
+// Push method of Array struct returns an array with the element appended
+func (arr *Array) Push(obj Object) *Array {
+	return &Array{elements: arr.vector().Push(obj)}
+}
+
+// Set method of Array struct returns an array with the element at the index replaced, which must be in range
+func (arr *Array) Set(i int, obj Object) *Array {
+	return &Array{elements: arr.vector().Set(i, obj)}
 }
 
 // Inspect method of Array struct
 func (arr *Array) Inspect() string {
 	var out bytes.Buffer
 	elements := []string{}
-	for _, element := range arr.Elements {
+	for _, element := range arr.Elements() {
 		elements = append(elements, element.Inspect())
 	}
 	out.WriteString("[")
@@ -202,6 +307,173 @@ func (arr *Array) Type() ObjectType {
 	return ARRAY_OBJ
 }
 
+// Tuple struct is an immutable sequence of a fixed number of objects
+type Tuple struct {
+	Elements []Object
+}
+
+// Inspect method of Tuple struct
+func (t *Tuple) Inspect() string {
+	elements := []string{}
+	for _, element := range t.Elements {
+		elements = append(elements, element.Inspect())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// Type method of Tuple struct
+func (t *Tuple) Type() ObjectType {
+	return TUPLE_OBJ
+}
+
+// IsHashable method of Tuple struct reports whether all the elements are hashable
+func (t *Tuple) IsHashable() bool {
+	return valuesHashable(t.Elements)
+}
+
+// HashKey method of Tuple struct combines the hash keys of the elements
+// it is expected to be called only when IsHashable holds
+func (t *Tuple) HashKey() HashKey {
+	return HashKey{
+		Type:  t.Type(),
+		Value: hashValues("tuple", t.Elements),
+	}
+}
+
+// Range struct
+/*
+	Range is a lazy sequence of integers from Start to End
+	End is excluded unless Inclusive is set, and the elements are never materialised unless requested
+*/
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool
+}
+
+// Inspect method of Range struct
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..=%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// Type method of Range struct
+func (r *Range) Type() ObjectType {
+	return RANGE_OBJ
+}
+
+// MaxRangeElements is the largest number of the elements of a range which Elements materialises
+const MaxRangeElements = 1 << 26
+
+// BigLen method of Range struct returns the number of the elements, which may not fit in int64, such as len(0..=9223372036854775807)
+func (r *Range) BigLen() *big.Int {
+	length := new(big.Int).Sub(big.NewInt(r.End), big.NewInt(r.Start))
+	if r.Inclusive {
+		length.Add(length, big.NewInt(1))
+	}
+	if length.Sign() < 0 {
+		return length.SetInt64(0)
+	}
+	return length
+}
+
+// Len method of Range struct returns the number of the elements
+// the second result is false if the number does not fit in int64, where every index of int64 not negative is in the range
+func (r *Range) Len() (int64, bool) {
+	length := r.BigLen()
+	if !length.IsInt64() {
+		return 0, false
+	}
+	return length.Int64(), true
+}
+
+// Empty method of Range struct
+func (r *Range) Empty() bool {
+	if r.Inclusive {
+		return r.End < r.Start
+	}
+	return r.End <= r.Start
+}
+
+// Contains method of Range struct reports whether idx is an index of the range
+func (r *Range) Contains(idx int64) bool {
+	length, ok := r.Len()
+	return 0 <= idx && (!ok || idx < length)
+}
+
+// At method of Range struct returns the idx-th element, idx is expected to be an index of the range
+func (r *Range) At(idx int64) int64 {
+	return r.Start + idx
+}
+
+// Last method of Range struct returns the last element, the range is expected not to be empty
+func (r *Range) Last() int64 {
+	if r.Inclusive {
+		return r.End
+	}
+	return r.End - 1
+}
+
+// Slice method of Range struct returns the range of the elements at the indices of bounds, which are clamped as slicing never fails
+func (r *Range) Slice(bounds *Range) *Range {
+	high := big.NewInt(bounds.End)
+	if bounds.Inclusive {
+		high.Add(high, big.NewInt(1))
+	}
+	return r.slice(big.NewInt(bounds.Start), high)
+}
+
+// Rest method of Range struct returns the range of the elements but the first
+func (r *Range) Rest() *Range {
+	return r.slice(big.NewInt(1), r.BigLen())
+}
+
+// slice method of Range struct returns the range of the elements from the low-th to before the high-th
+// the end is excluded as in low..high, unless it does not fit in int64
+func (r *Range) slice(low, high *big.Int) *Range {
+	length := r.BigLen()
+	clamp := func(x, min *big.Int) *big.Int {
+		if x.Cmp(min) < 0 {
+			return min
+		}
+		if x.Cmp(length) > 0 {
+			return length
+		}
+		return x
+	}
+	low = clamp(low, big.NewInt(0))
+	high = clamp(high, low)
+	start := new(big.Int).Add(big.NewInt(r.Start), low)
+	stop := new(big.Int).Add(big.NewInt(r.Start), high)
+	switch {
+	case stop.IsInt64():
+		return &Range{Start: start.Int64(), End: stop.Int64()}
+	case start.Cmp(stop) < 0: // ends at 9223372036854775807
+		return &Range{Start: start.Int64(), End: math.MaxInt64, Inclusive: true}
+	default:
+		return &Range{Start: math.MaxInt64, End: math.MaxInt64}
+	}
+}
+
+// Elements method of Range struct materialises the range
+// it fails if the range has more than MaxRangeElements elements
+func (r *Range) Elements() ([]Object, error) {
+	length, ok := r.Len()
+	if !ok || length > MaxRangeElements {
+		return nil, fmt.Errorf("range too long to materialise: %s elements, the limit is %d", r.BigLen(), MaxRangeElements)
+	}
+	elements := make([]Object, length, length)
+	for i := int64(0); i < length; i++ {
+		elements[i] = &Integer{Value: r.At(i)}
+	}
+	return elements, nil
+}
+
 // HashKey struct
 type HashKey struct {
 	Type  ObjectType
@@ -235,16 +507,58 @@ func (i *Integer) HashKey() HashKey {
 	}
 }
 
+// HashKey method of BigInt struct
+func (bi *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(bi.Value.String()))
+	return HashKey{
+		Type:  bi.Type(),
+		Value: h.Sum64(),
+	}
+}
+
 // HashKey method of String struct
+// the UTF-8 bytes are hashed, which are the same exactly when the runes are
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
-	h.Write([]byte(s.Value)) // ? not []rune
+	h.Write([]byte(s.Value))
 	return HashKey{
 		Type:  s.Type(),
 		Value: h.Sum64(),
 	}
 }
 
+// HashKey method of Null struct
+func (n *Null) HashKey() HashKey {
+	return HashKey{Type: n.Type()}
+}
+
+// IsHashable method of Array struct reports whether all the elements are hashable
+func (arr *Array) IsHashable() bool {
+	return valuesHashable(arr.Elements())
+}
+
+// HashKey method of Array struct combines the hash keys of the elements
+// it is expected to be called only when IsHashable holds
+func (arr *Array) HashKey() HashKey {
+	return HashKey{
+		Type:  arr.Type(),
+		Value: hashValues("array", arr.Elements()),
+	}
+}
+
+// HashKeyOf function returns the hash key of obj, or false if obj is unusable as a hash key
+func HashKeyOf(obj Object) (HashKey, bool) {
+	if composite, ok := obj.(CompositeHashable); ok && !composite.IsHashable() {
+		return HashKey{}, false
+	}
+	hashable, ok := obj.(Hashable)
+	if !ok {
+		return HashKey{}, false
+	}
+	return hashable.HashKey(), true
+}
+
 // HashPair struct
 type HashPair struct {
 	Key   Object
@@ -252,8 +566,99 @@ type HashPair struct {
 }
 
 // Hash struct
+/*
+	The keys and the values are kept in persistent vectors in insertion order, so that the hashes are inspected and iterated deterministically.
+	Their indices are kept in a persistent hash array mapped trie by the hash keys of the keys, and the keys of the same hash key are told apart by Equal,
+	so that the keys whose hash keys collide never overwrite each other.
+	Assoc makes a new hash in O(log n) sharing the pairs, and Set replaces the contents of the hash by it while the hash is being built.
+	The zero value is an empty hash.
+*/
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	keys    *Vector
+	values  *Vector
+	buckets *hamtNode
+}
+
+// NewHash function creates an empty hash
+func NewHash() *Hash {
+	return &Hash{keys: emptyVector, values: emptyVector, buckets: &hamtNode{}}
+}
+
+// index method of Hash struct returns the indices of the hash key, and the index of the key among them, or -1 if there is no such key
+func (h *Hash) index(hashed HashKey, key Object) ([]int, int) {
+	if h.buckets == nil {
+		return nil, -1
+	}
+	indices, _ := h.buckets.get(hashed, 0)
+	for _, i := range indices {
+		if Equal(h.keys.At(i), key) {
+			return indices, i
+		}
+	}
+	return indices, -1
+}
+
+// Get method of Hash struct returns the value of the key, or false if there is no such key
+func (h *Hash) Get(key Object) (Object, bool) {
+	hashed, ok := HashKeyOf(key)
+	if !ok {
+		return nil, false
+	}
+	if _, i := h.index(hashed, key); i >= 0 {
+		return h.values.At(i), true
+	}
+	return nil, false
+}
+
+// Assoc method of Hash struct returns a hash with the pair put at the end, or with the value of an equal key replaced in place
+// it returns false if the key is unusable as a hash key
+func (h *Hash) Assoc(key, value Object) (*Hash, bool) {
+	hashed, ok := HashKeyOf(key)
+	if !ok {
+		return nil, false
+	}
+	if h.buckets == nil {
+		h = NewHash()
+	}
+	indices, i := h.index(hashed, key)
+	if i >= 0 {
+		return &Hash{keys: h.keys, values: h.values.Set(i, value), buckets: h.buckets}, true
+	}
+	added := make([]int, len(indices)+1)
+	copy(added, indices)
+	added[len(indices)] = h.keys.Len()
+	return &Hash{
+		keys:    h.keys.Push(key),
+		values:  h.values.Push(value),
+		buckets: h.buckets.assoc(hashed, added, 0),
+	}, true
+}
+
+// Set method of Hash struct puts the pair as Assoc, but into the hash itself
+// it returns false if the key is unusable as a hash key
+func (h *Hash) Set(key, value Object) bool {
+	assoc, ok := h.Assoc(key, value)
+	if ok {
+		*h = *assoc
+	}
+	return ok
+}
+
+// Len method of Hash struct returns the number of the pairs
+func (h *Hash) Len() int {
+	if h.keys == nil {
+		return 0
+	}
+	return h.keys.Len()
+}
+
+// Pairs method of Hash struct returns all the pairs in insertion order
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, h.Len())
+	for i := range pairs {
+		pairs[i] = HashPair{Key: h.keys.At(i), Value: h.values.At(i)}
+	}
+	return pairs
 }
 
 // Type method of Hash struct
@@ -265,7 +670,7 @@ func (h *Hash) Type() ObjectType {
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 	out.WriteString("{")
@@ -274,6 +679,227 @@ func (h *Hash) Inspect() string {
 	return out.String()
 }
 
+// Struct struct is a record type declared by a struct statement
+// calling it constructs a record, taking the values of the fields in order
+type Struct struct {
+	Name   string
+	Fields []string
+}
+
+// Type method of Struct struct
+func (s *Struct) Type() ObjectType {
+	return STRUCT_OBJ
+}
+
+// Inspect method of Struct struct
+func (s *Struct) Inspect() string {
+	return "struct " + s.Name + " { " + strings.Join(s.Fields, ", ") + " }"
+}
+
+// FieldIndex method of Struct struct returns the position of the field, or -1 if there is no such field
+func (s *Struct) FieldIndex(name string) int {
+	for i, field := range s.Fields {
+		if field == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record struct is an instance of a Struct
+// Values[i] is the value of the field Struct.Fields[i], and records are never modified after construction
+type Record struct {
+	Struct *Struct
+	Values []Object
+}
+
+// Type method of Record struct
+func (r *Record) Type() ObjectType {
+	return RECORD_OBJ
+}
+
+// Inspect method of Record struct
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, field := range r.Struct.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %s", field, r.Values[i].Inspect()))
+	}
+	out.WriteString(r.Struct.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// Get method of Record struct returns the value of the field
+func (r *Record) Get(name string) (Object, bool) {
+	idx := r.Struct.FieldIndex(name)
+	if idx < 0 {
+		return nil, false
+	}
+	return r.Values[idx], true
+}
+
+// IsHashable method of Record struct reports whether every field can be used as a hash key
+func (r *Record) IsHashable() bool {
+	return valuesHashable(r.Values)
+}
+
+// HashKey method of Record struct combines the hash keys of the fields
+// it is expected to be called only when IsHashable holds
+func (r *Record) HashKey() HashKey {
+	return HashKey{
+		Type:  r.Type(),
+		Value: hashValues(r.Struct.Name, r.Values),
+	}
+}
+
+// Enum struct is a type declared by an enum statement
+type Enum struct {
+	Name     string
+	Variants []*Variant
+}
+
+// Type method of Enum struct
+func (e *Enum) Type() ObjectType {
+	return ENUM_OBJ
+}
+
+// Inspect method of Enum struct
+func (e *Enum) Inspect() string {
+	variants := []string{}
+	for _, v := range e.Variants {
+		variants = append(variants, v.declaration())
+	}
+	return "enum " + e.Name + " { " + strings.Join(variants, ", ") + " }"
+}
+
+// Variant method of Enum struct returns the variant of the name
+func (e *Enum) Variant(name string) (*Variant, bool) {
+	for _, v := range e.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Variant struct is a variant of an Enum
+/*
+	A variant carrying payloads is a constructor of the enum values, taking the payloads in order.
+	A variant carrying nothing has the only value Unit, and is never called.
+*/
+type Variant struct {
+	Enum   *Enum
+	Name   string
+	Fields []string
+	Unit   *EnumValue // nil if the variant carries payloads
+}
+
+// Type method of Variant struct
+func (v *Variant) Type() ObjectType {
+	return VARIANT_OBJ
+}
+
+// Inspect method of Variant struct
+func (v *Variant) Inspect() string {
+	return "variant " + v.Enum.Name + "." + v.declaration()
+}
+
+// declaration method of Variant struct
+func (v *Variant) declaration() string {
+	if len(v.Fields) == 0 {
+		return v.Name
+	}
+	return v.Name + "(" + strings.Join(v.Fields, ", ") + ")"
+}
+
+// EnumValue struct is a value of an Enum, tagged by its Variant
+// Values[i] is the payload Variant.Fields[i]
+type EnumValue struct {
+	Variant *Variant
+	Values  []Object
+}
+
+// Type method of EnumValue struct
+func (ev *EnumValue) Type() ObjectType {
+	return ENUM_VALUE_OBJ
+}
+
+// Inspect method of EnumValue struct
+func (ev *EnumValue) Inspect() string {
+	var out bytes.Buffer
+	out.WriteString(ev.Variant.Enum.Name + "." + ev.Variant.Name)
+	if len(ev.Values) == 0 {
+		return out.String()
+	}
+	values := []string{}
+	for _, value := range ev.Values {
+		values = append(values, value.Inspect())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(values, ", "))
+	out.WriteString(")")
+	return out.String()
+}
+
+// Get method of EnumValue struct returns the payload of the name
+func (ev *EnumValue) Get(name string) (Object, bool) {
+	for i, field := range ev.Variant.Fields {
+		if field == name {
+			return ev.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// IsHashable method of EnumValue struct reports whether every payload can be used as a hash key
+func (ev *EnumValue) IsHashable() bool {
+	return valuesHashable(ev.Values)
+}
+
+// HashKey method of EnumValue struct combines the hash keys of the payloads
+// it is expected to be called only when IsHashable holds
+func (ev *EnumValue) HashKey() HashKey {
+	return HashKey{
+		Type:  ev.Type(),
+		Value: hashValues(ev.Variant.Enum.Name+"."+ev.Variant.Name, ev.Values),
+	}
+}
+
+// CompositeHashable interface is implemented by the hashable objects containing other objects
+// they can be used as hash keys only if IsHashable holds
+type CompositeHashable interface {
+	Hashable
+	IsHashable() bool
+}
+
+// valuesHashable function reports whether every value can be used as a hash key
+func valuesHashable(values []Object) bool {
+	for _, value := range values {
+		if composite, ok := value.(CompositeHashable); ok && !composite.IsHashable() {
+			return false
+		}
+		if _, ok := value.(Hashable); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hashValues function combines the tag and the hash keys of the values
+func hashValues(tag string, values []Object) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(tag))
+	for _, value := range values {
+		key := value.(Hashable).HashKey()
+		h.Write([]byte(key.Type))
+		binary.Write(h, binary.LittleEndian, key.Value)
+	}
+	return h.Sum64()
+}
+
 // Quote struct
 type Quote struct {
 	Node ast.Node
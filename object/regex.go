@@ -0,0 +1,48 @@
+package object
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// Regex struct is a compiled regular expression, in the syntax of Go (RE2)
+type Regex struct {
+	Value *regexp.Regexp
+}
+
+// Type method of Regex struct
+func (r *Regex) Type() ObjectType {
+	return REGEX_OBJ
+}
+
+// Inspect method of Regex struct returns the regex as a literal, such as r"\d+"
+func (r *Regex) Inspect() string {
+	var out strings.Builder
+	out.WriteString(`r"`)
+	pattern := r.Value.String()
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern): // the escape sequences are left as they are
+			out.WriteString(pattern[i : i+2])
+			i++
+		case pattern[i] == '"':
+			out.WriteString(`\"`)
+		default:
+			out.WriteByte(pattern[i])
+		}
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
+
+// HashKey method of Regex struct
+// the regexes of the same pattern are equal
+func (r *Regex) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(r.Value.String()))
+	return HashKey{
+		Type:  r.Type(),
+		Value: h.Sum64(),
+	}
+}
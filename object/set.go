@@ -0,0 +1,157 @@
+package object
+
+import (
+	"sort"
+	"strings"
+)
+
+// Set struct is an unordered collection of hashable objects without duplicates
+/*
+	Sets are immutable as the other collections: the operations return new sets.
+	The elements are inspected and iterated in a deterministic order given by Sorted.
+*/
+type Set struct {
+	buckets map[HashKey][]Object
+	size    int
+}
+
+// NewSet function creates an empty set
+func NewSet() *Set {
+	return &Set{buckets: make(map[HashKey][]Object)}
+}
+
+// Type method of Set struct
+func (s *Set) Type() ObjectType {
+	return SET_OBJ
+}
+
+// Inspect method of Set struct
+func (s *Set) Inspect() string {
+	elements := []string{}
+	for _, element := range s.Sorted() {
+		elements = append(elements, element.Inspect())
+	}
+	return "#{" + strings.Join(elements, ", ") + "}"
+}
+
+// HashKey method of Set struct
+func (s *Set) HashKey() HashKey {
+	return HashKey{
+		Type:  s.Type(),
+		Value: hashValues("set", s.Sorted()),
+	}
+}
+
+// Iterator method of Set struct iterates over the elements in the order of Sorted
+func (s *Set) Iterator() Iterator {
+	return &sliceIterator{elements: s.Sorted()}
+}
+
+// Add method of Set struct puts the element unless an equal one is already in the set
+// it returns false if the element is unusable as a set element
+func (s *Set) Add(element Object) bool {
+	hashed, ok := HashKeyOf(element)
+	if !ok {
+		return false
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[HashKey][]Object)
+	}
+	for _, other := range s.buckets[hashed] {
+		if Equal(other, element) {
+			return true
+		}
+	}
+	s.buckets[hashed] = append(s.buckets[hashed], element)
+	s.size++
+	return true
+}
+
+// Has method of Set struct reports whether the set contains the element
+func (s *Set) Has(element Object) bool {
+	hashed, ok := HashKeyOf(element)
+	if !ok {
+		return false
+	}
+	for _, other := range s.buckets[hashed] {
+		if Equal(other, element) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len method of Set struct returns the number of the elements
+func (s *Set) Len() int {
+	return s.size
+}
+
+// elements method of Set struct returns the elements in no particular order
+func (s *Set) elements() []Object {
+	elements := make([]Object, 0, s.size)
+	for _, bucket := range s.buckets {
+		elements = append(elements, bucket...)
+	}
+	return elements
+}
+
+// Union method of Set struct returns the set of the elements in s or other
+func (s *Set) Union(other *Set) *Set {
+	union := NewSet()
+	for _, element := range s.elements() {
+		union.Add(element)
+	}
+	for _, element := range other.elements() {
+		union.Add(element)
+	}
+	return union
+}
+
+// Intersection method of Set struct returns the set of the elements in both s and other
+func (s *Set) Intersection(other *Set) *Set {
+	intersection := NewSet()
+	for _, element := range s.elements() {
+		if other.Has(element) {
+			intersection.Add(element)
+		}
+	}
+	return intersection
+}
+
+// Difference method of Set struct returns the set of the elements in s but not in other
+func (s *Set) Difference(other *Set) *Set {
+	difference := NewSet()
+	for _, element := range s.elements() {
+		if !other.Has(element) {
+			difference.Add(element)
+		}
+	}
+	return difference
+}
+
+// Sorted method of Set struct returns the elements in a deterministic order
+// the numbers come first, and the others are grouped by their types
+// ordered objects, such as numbers and strings, are sorted by Compare, and the others by Inspect
+func (s *Set) Sorted() []Object {
+	elements := s.elements()
+	group := func(obj Object) ObjectType {
+		switch obj.Type() {
+		case INTEGER_OBJ, BIGINT_OBJ, DECIMAL_OBJ:
+			return ""
+		}
+		return obj.Type()
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		if group(elements[i]) != group(elements[j]) {
+			return group(elements[i]) < group(elements[j])
+		}
+		if order, ok := Compare(elements[i], elements[j]); ok && order != 0 {
+			return order < 0
+		}
+		if elements[i].Inspect() != elements[j].Inspect() {
+			return elements[i].Inspect() < elements[j].Inspect()
+		}
+		return elements[i].(Hashable).HashKey().Value < elements[j].(Hashable).HashKey().Value
+	})
+	return elements
+}
@@ -0,0 +1,50 @@
+package object
+
+import "time"
+
+// Time struct is an instant with the time zone in which it is shown
+type Time struct {
+	Value time.Time
+}
+
+// Type method of Time struct
+func (t *Time) Type() ObjectType {
+	return TIME_OBJ
+}
+
+// Inspect method of Time struct returns the time in RFC 3339, such as 2024-01-02T15:04:05+09:00
+func (t *Time) Inspect() string {
+	return t.Value.Format(time.RFC3339Nano)
+}
+
+// HashKey method of Time struct
+// the times of the same instant have the same hash key, whatever their time zones are, as they are equal
+func (t *Time) HashKey() HashKey {
+	return HashKey{
+		Type:  t.Type(),
+		Value: uint64(t.Value.UnixNano()),
+	}
+}
+
+// Duration struct is an amount of time, in nanoseconds
+type Duration struct {
+	Value time.Duration
+}
+
+// Type method of Duration struct
+func (d *Duration) Type() ObjectType {
+	return DURATION_OBJ
+}
+
+// Inspect method of Duration struct returns the duration such as 1h30m0s
+func (d *Duration) Inspect() string {
+	return d.Value.String()
+}
+
+// HashKey method of Duration struct
+func (d *Duration) HashKey() HashKey {
+	return HashKey{
+		Type:  d.Type(),
+		Value: uint64(d.Value),
+	}
+}
@@ -0,0 +1,157 @@
+package object
+
+// Vector struct is a persistent vector of objects
+/*
+	The elements are kept in a trie of 32-way nodes, and the last at most 32 elements in a separate tail, as in Clojure.
+	Push and Set copy only the path to the changed leaf, that is O(log n), and share the rest with the original vector,
+	which is never changed.
+*/
+type Vector struct {
+	count int
+	shift uint
+	root  *vectorNode
+	tail  []Object
+}
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// vectorNode struct is a node of the trie: the inner nodes have children, and the leaves have values
+type vectorNode struct {
+	children []*vectorNode
+	values   []Object
+}
+
+var emptyVector = &Vector{shift: vectorBits, root: &vectorNode{children: make([]*vectorNode, vectorWidth)}}
+
+// NewVector function creates a vector of the elements
+func NewVector(elements []Object) *Vector {
+	v := emptyVector
+	for len(elements) > vectorWidth {
+		leaf := make([]Object, vectorWidth)
+		copy(leaf, elements[:vectorWidth])
+		v = &Vector{count: v.count + vectorWidth, shift: v.shift, root: v.root, tail: leaf}
+		elements = elements[vectorWidth:]
+		v = v.pushTail()
+	}
+	tail := make([]Object, len(elements))
+	copy(tail, elements)
+	return &Vector{count: v.count + len(tail), shift: v.shift, root: v.root, tail: tail}
+}
+
+// Len method of Vector struct returns the number of the elements
+func (v *Vector) Len() int {
+	return v.count
+}
+
+// tailOffset method of Vector struct returns the index of the first element in the tail
+func (v *Vector) tailOffset() int {
+	return v.count - len(v.tail)
+}
+
+// leaf method of Vector struct returns the values of the leaf which contains the index
+func (v *Vector) leaf(i int) []Object {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.children[(i>>level)&vectorMask]
+	}
+	return node.values
+}
+
+// At method of Vector struct returns the element at the index, which must be in range
+func (v *Vector) At(i int) Object {
+	return v.leaf(i)[i&vectorMask]
+}
+
+// Elements method of Vector struct returns the elements in a new slice
+func (v *Vector) Elements() []Object {
+	elements := make([]Object, 0, v.count)
+	for i := 0; i < v.count; i += vectorWidth {
+		elements = append(elements, v.leaf(i)...)
+	}
+	return elements
+}
+
+// Push method of Vector struct returns a vector with the element appended
+func (v *Vector) Push(obj Object) *Vector {
+	if len(v.tail) < vectorWidth {
+		tail := make([]Object, len(v.tail)+1)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = obj
+		return &Vector{count: v.count + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+	pushed := v.pushTail()
+	pushed.count++
+	pushed.tail = []Object{obj}
+	return pushed
+}
+
+// pushTail method of Vector struct returns a vector whose trie contains the full tail, with the tail left empty
+func (v *Vector) pushTail() *Vector {
+	tail := &vectorNode{values: v.tail}
+	if (v.count >> vectorBits) > (1 << v.shift) { // the root is full
+		root := &vectorNode{children: make([]*vectorNode, vectorWidth)}
+		root.children[0] = v.root
+		root.children[1] = newVectorPath(v.shift, tail)
+		return &Vector{count: v.count, shift: v.shift + vectorBits, root: root}
+	}
+	return &Vector{count: v.count, shift: v.shift, root: v.pushTailInto(v.shift, v.root, tail)}
+}
+
+// pushTailInto method of Vector struct copies the path of the node to the place of the tail, and puts the tail there
+func (v *Vector) pushTailInto(level uint, node, tail *vectorNode) *vectorNode {
+	i := ((v.count - 1) >> level) & vectorMask
+	copied := &vectorNode{children: make([]*vectorNode, vectorWidth)}
+	copy(copied.children, node.children)
+	switch {
+	case level == vectorBits:
+		copied.children[i] = tail
+	case node.children[i] != nil:
+		copied.children[i] = v.pushTailInto(level-vectorBits, node.children[i], tail)
+	default:
+		copied.children[i] = newVectorPath(level-vectorBits, tail)
+	}
+	return copied
+}
+
+// newVectorPath function makes a chain of nodes from the level down to the leaf
+func newVectorPath(level uint, leaf *vectorNode) *vectorNode {
+	if level == 0 {
+		return leaf
+	}
+	node := &vectorNode{children: make([]*vectorNode, vectorWidth)}
+	node.children[0] = newVectorPath(level-vectorBits, leaf)
+	return node
+}
+
+// Set method of Vector struct returns a vector with the element at the index replaced, which must be in range
+func (v *Vector) Set(i int, obj Object) *Vector {
+	if i >= v.tailOffset() {
+		tail := make([]Object, len(v.tail))
+		copy(tail, v.tail)
+		tail[i&vectorMask] = obj
+		return &Vector{count: v.count, shift: v.shift, root: v.root, tail: tail}
+	}
+	return &Vector{count: v.count, shift: v.shift, root: setInVector(v.root, v.shift, i, obj), tail: v.tail}
+}
+
+// setInVector function copies the path of the node to the index, and replaces the element there
+func setInVector(node *vectorNode, level uint, i int, obj Object) *vectorNode {
+	if level == 0 {
+		values := make([]Object, len(node.values))
+		copy(values, node.values)
+		values[i&vectorMask] = obj
+		return &vectorNode{values: values}
+	}
+	copied := &vectorNode{children: make([]*vectorNode, vectorWidth)}
+	copy(copied.children, node.children)
+	j := (i >> level) & vectorMask
+	copied.children[j] = setInVector(node.children[j], level-vectorBits, i, obj)
+	return copied
+}
@@ -0,0 +1,132 @@
+package object
+
+import (
+	"fmt"
+	"testing"
+)
+
+func integers(n int) []Object {
+	elements := make([]Object, n)
+	for i := range elements {
+		elements[i] = &Integer{Value: int64(i)}
+	}
+	return elements
+}
+
+func TestVector(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 33, 64, 65, 1024, 1025, 32*32*32 + 33} {
+		built := NewVector(integers(n))
+		pushed := emptyVector
+		for _, element := range integers(n) {
+			pushed = pushed.Push(element)
+		}
+		for _, v := range []*Vector{built, pushed} {
+			if v.Len() != n {
+				t.Fatalf("wrong length. got=%d, want=%d", v.Len(), n)
+			}
+			for i, element := range v.Elements() {
+				if element.(*Integer).Value != int64(i) || v.At(i) != element {
+					t.Fatalf("wrong element at %d of %d. got=%s", i, n, element.Inspect())
+				}
+			}
+		}
+	}
+}
+
+func TestVectorIsPersistent(t *testing.T) {
+	original := NewVector(integers(100))
+	pushed := original.Push(&Integer{Value: 100})
+	set := original.Set(10, &Integer{Value: -1}).Set(99, &Integer{Value: -2})
+
+	if original.Len() != 100 || pushed.Len() != 101 {
+		t.Fatalf("wrong lengths. got=%d and %d", original.Len(), pushed.Len())
+	}
+	if original.At(10).Inspect() != "10" || original.At(99).Inspect() != "99" {
+		t.Errorf("original vector is changed by Set")
+	}
+	if set.At(10).Inspect() != "-1" || set.At(99).Inspect() != "-2" || set.At(11).Inspect() != "11" {
+		t.Errorf("wrong elements after Set. got=%s, %s, %s", set.At(10).Inspect(), set.At(99).Inspect(), set.At(11).Inspect())
+	}
+}
+
+func TestHashAssoc(t *testing.T) {
+	h := NewHash()
+	for i := 0; i < 2000; i++ {
+		h.Set(&String{Value: fmt.Sprint(i)}, &Integer{Value: int64(i)})
+	}
+	original := h
+	updated, _ := h.Assoc(&String{Value: "7"}, &Integer{Value: -7})
+	added, _ := updated.Assoc(&String{Value: "new"}, &Null{})
+
+	if original.Len() != 2000 || updated.Len() != 2000 || added.Len() != 2001 {
+		t.Fatalf("wrong lengths. got=%d, %d, %d", original.Len(), updated.Len(), added.Len())
+	}
+	for i := 0; i < 2000; i++ {
+		value, ok := original.Get(&String{Value: fmt.Sprint(i)})
+		if !ok || value.(*Integer).Value != int64(i) {
+			t.Fatalf("wrong value of %d. got=%v", i, value)
+		}
+	}
+	if value, _ := updated.Get(&String{Value: "7"}); value.Inspect() != "-7" {
+		t.Errorf("wrong value after Assoc. got=%s", value.Inspect())
+	}
+	if pairs := added.Pairs(); pairs[7].Value.Inspect() != "-7" || pairs[2000].Key.Inspect() != "new" {
+		t.Errorf("Assoc does not keep insertion order")
+	}
+
+	// the hash keys of 1 and true agree in all the bits of their values
+	same := NewHash()
+	same.Set(&Integer{Value: 1}, &String{Value: "integer"})
+	same.Set(&Boolean{Value: true}, &String{Value: "boolean"})
+	if value, _ := same.Get(&Integer{Value: 1}); same.Len() != 2 || value.Inspect() != "integer" {
+		t.Errorf("keys of different types overwrite each other")
+	}
+}
+
+func BenchmarkArrayPush(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		arr := &Array{}
+		for j := 0; j < 1000; j++ {
+			arr = arr.Push(&Integer{Value: int64(j)})
+		}
+	}
+}
+
+// BenchmarkCopyingPush function measures the push which copies all the elements, as the arrays used to do
+func BenchmarkCopyingPush(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		elements := []Object{}
+		for j := 0; j < 1000; j++ {
+			pushed := make([]Object, len(elements)+1)
+			copy(pushed, elements)
+			pushed[len(elements)] = &Integer{Value: int64(j)}
+			elements = pushed
+		}
+	}
+}
+
+func BenchmarkHashAssoc(b *testing.B) {
+	keys := integers(1000)
+	for i := 0; i < b.N; i++ {
+		h := NewHash()
+		for _, key := range keys {
+			h, _ = h.Assoc(key, key)
+		}
+	}
+}
+
+// BenchmarkCopyingHashAssoc function measures the update which copies all the pairs into a new map
+func BenchmarkCopyingHashAssoc(b *testing.B) {
+	keys := integers(1000)
+	for i := 0; i < b.N; i++ {
+		pairs := map[HashKey]HashPair{}
+		for _, key := range keys {
+			copied := make(map[HashKey]HashPair, len(pairs)+1)
+			for k, pair := range pairs {
+				copied[k] = pair
+			}
+			copied[key.(Hashable).HashKey()] = HashPair{Key: key, Value: key}
+			pairs = copied
+		}
+	}
+}
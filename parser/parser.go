@@ -2,7 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/BOBO1997/monkey/ast"
 	"github.com/BOBO1997/monkey/lexer"
@@ -19,6 +22,8 @@ type Parser struct {
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	sawYield bool // whether yield appeared in the body of the function being parsed
 }
 
 type (
@@ -36,23 +41,31 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
+	p.registerPrefix(token.BYTES, p.parseBytesLiteral)
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNullLiteral)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.HASHBRACE, p.parseSetLiteral)
 	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
@@ -61,6 +74,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.GEQ, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.QLBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseDotExpression)
+	p.registerInfix(token.QDOT, p.parseDotExpression)
+	p.registerInfix(token.WITH, p.parseUpdateExpression)
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	p.registerInfix(token.DOTDOT, p.parseInfixExpression)
+	p.registerInfix(token.DOTDOTEQ, p.parseInfixExpression)
 
 	p.nextToken() // go forward
 	p.nextToken() // go forward
@@ -103,22 +123,45 @@ func (p *Parser) ParseProgram() *ast.Program {
 func (p *Parser) parseStatement() ast.Statement { // note: ast.Statement is an interface
 	switch p.curToken.Type {
 	case token.LET:
+		if p.peekTokenIs(token.LPAREN) {
+			return p.parseUnpackStatement()
+		}
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.DEFER:
+		return p.parseDeferStatement()
+	case token.YIELD:
+		return p.parseYieldStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
+	case token.ENUM:
+		return p.parseEnumStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
 // parseLetStatement method of Parser struct parses a let statement
-// let statement is expected to be "let <identifier> = <expression>"
+// let statement is expected to be "let <identifier> = <expression>" or "let <identifier>: <type> = <expression>"
 func (p *Parser) parseLetStatement() *ast.LetStatement { // note: ast.LetStatement is a struct
 	stmt := &ast.LetStatement{Token: p.curToken}
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Type = p.parseTypeAnnotation()
+	}
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -126,6 +169,46 @@ func (p *Parser) parseLetStatement() *ast.LetStatement { // note: ast.LetStateme
 	// TODO: expression
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
+	if function, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		function.Name = stmt.Name.Value
+	}
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// parseUnpackStatement method of Parser struct parses a let statement unpacking a tuple
+// unpack statement is expected to be "let (<identifier>, <identifier>, ...) = <expression>"
+func (p *Parser) parseUnpackStatement() *ast.UnpackStatement {
+	stmt := &ast.UnpackStatement{Token: p.curToken}
+	p.nextToken() // skip "let"
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(token.RPAREN) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if seen[name.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("duplicate name %s in let", name.Value))
+			return nil
+		}
+		seen[name.Value] = true
+		stmt.Names = append(stmt.Names, name)
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // skip ")"
+	if len(stmt.Names) == 0 {
+		p.errors = append(p.errors, "expected names to unpack in let")
+		return nil
+	}
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -133,13 +216,186 @@ func (p *Parser) parseLetStatement() *ast.LetStatement { // note: ast.LetStateme
 }
 
 // parseReturnStatement method of Parser struct parses a return statement
-// return statement is expected to be "return <expression>"
+// return statement is expected to be "return <expression>", and "return <expression>, <expression>, ..." returns a tuple
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 	p.nextToken()
 
 	// TODO: expression
 	stmt.ReturnValue = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COMMA) {
+		tuple := &ast.TupleLiteral{Token: token.Token{Type: token.LPAREN, Literal: "("}, Elements: []ast.Expression{stmt.ReturnValue}}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+		}
+		stmt.ReturnValue = tuple
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseThrowStatement method of Parser struct parses a throw statement
+// throw statement is expected to be "throw <expression>"
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseDeferStatement method of Parser struct parses a defer statement
+// defer statement is expected to be "defer <call expression>"
+func (p *Parser) parseDeferStatement() *ast.DeferStatement {
+	stmt := &ast.DeferStatement{Token: p.curToken}
+	p.nextToken()
+
+	call, ok := p.parseExpression(LOWEST).(*ast.CallExpression)
+	if !ok {
+		p.errors = append(p.errors, "expression in defer must be function call")
+		return nil
+	}
+	stmt.Call = call
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseFunctionStatement method of Parser struct parses a named function declaration
+// function declaration is expected to be "fn <identifier>(<parameters>) <body>"
+func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+	p.nextToken()
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	literal := &ast.FunctionLiteral{
+		Token: stmt.Token,
+		Name:  stmt.Name.Value,
+	}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	if !p.parseFunctionSignature(literal) {
+		return nil
+	}
+	p.parseFunctionBody(literal)
+	stmt.Function = literal
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseStructStatement method of Parser struct parses a struct declaration
+// struct declaration is expected to be "struct <identifier> { <field>, <field>, ... }"
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if seen[field.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("duplicate field %s in struct %s", field.Value, stmt.Name.Value))
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseEnumStatement method of Parser struct parses an enum declaration
+// enum declaration is expected to be "enum <identifier> { <variant>, <variant>(<field>, ...), ... }"
+func (p *Parser) parseEnumStatement() *ast.EnumStatement {
+	stmt := &ast.EnumStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		variant := &ast.EnumVariant{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+		if seen[variant.Name.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("duplicate variant %s in enum %s", variant.Name.Value, stmt.Name.Value))
+			return nil
+		}
+		seen[variant.Name.Value] = true
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			variant.Fields = p.parseFunctionParameters()
+		}
+		stmt.Variants = append(stmt.Variants, variant)
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseYieldStatement method of Parser struct parses a yield statement
+// yield statement is expected to be "yield <expression>" or "yield ...<expression>"
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+	p.sawYield = true
+	p.nextToken()
+	if p.curTokenIs(token.ELLIPSIS) {
+		stmt.Delegate = true
+		p.nextToken()
+	}
+
+	stmt.Value = p.parseExpression(LOWEST)
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -171,30 +427,43 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 // priority depth of each operator
 const (
-	_           int = iota
-	LOWEST          // 0
-	EQUALS          // ==
-	LESSGREATER     // > or <
-	SUM             // + or -
-	PRODUCT         // * or /
-	PREFIX          // -X or !X
-	CALL            // myFunction(X)
-	INDEX           // array[index]
+	_            int = iota
+	LOWEST           // 0
+	NULLISH          // ??
+	EQUALS           // ==
+	LESSGREATER      // > or <
+	RANGE            // .. or ..=
+	UNION            // |
+	INTERSECTION     // &
+	SUM              // + or -
+	PRODUCT          // * or /
+	PREFIX           // -X or !X
+	CALL             // myFunction(X)
+	INDEX            // array[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NEQ:      EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.LEQ:      LESSGREATER,
-	token.GEQ:      LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.ASTERISK: PRODUCT,
-	token.SLASH:    PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.EQ:        EQUALS,
+	token.NEQ:       EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.LEQ:       LESSGREATER,
+	token.GEQ:       LESSGREATER,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.ASTERISK:  PRODUCT,
+	token.SLASH:     PRODUCT,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
+	token.QLBRACKET: INDEX,
+	token.DOT:       INDEX,
+	token.QDOT:      INDEX,
+	token.WITH:      CALL,
+	token.NULLISH:   NULLISH,
+	token.DOTDOT:    RANGE,
+	token.DOTDOTEQ:  RANGE,
+	token.PIPE:      UNION,
+	token.AMPERSAND: INTERSECTION,
 }
 
 // parseExpressionStatement method of Parser struct parses expression statement
@@ -243,6 +512,9 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: p.curToken}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64) // change string to int
 	if err != nil {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok { // too large for int64
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: bigValue}
+		}
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
 		return nil
@@ -251,6 +523,116 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return literal
 }
 
+// parseDecimalLiteral method of Parser struct returns ast.Expression interface, which contains a decimal literal
+// decimal expression is expected to be "<digits>[.<digits>]d;"
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	literal := &ast.DecimalLiteral{Token: p.curToken}
+	digits := strings.TrimSuffix(p.curToken.Literal, "d")
+	if point := strings.IndexByte(digits, '.'); point >= 0 {
+		literal.Scale = len(digits) - point - 1
+		digits = digits[:point] + digits[point+1:]
+	}
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		msg := fmt.Sprintf("could not parse %q as decimal", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	literal.Value = value
+	return literal
+}
+
+// parseBytesLiteral method of Parser struct returns ast.Expression interface, which contains a bytes literal
+// bytes expression is expected to be "b\"<characters or escapes>\"", where the escapes are \xHH, \n, \t, \r, \0, \\ and \"
+func (p *Parser) parseBytesLiteral() ast.Expression {
+	literal := &ast.BytesLiteral{Token: p.curToken}
+	source := p.curToken.Literal
+	if len(source) < 3 || !strings.HasSuffix(source, "\"") {
+		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as bytes: unterminated literal", source))
+		return nil
+	}
+	value, err := unescapeBytes(source[2 : len(source)-1])
+	if err != nil {
+		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as bytes: %s", source, err))
+		return nil
+	}
+	literal.Value = value
+	return literal
+}
+
+// parseRegexLiteral method of Parser struct returns ast.Expression interface, which contains a compiled regex
+// regex expression is expected to be "r\"<pattern>\"", where \" is a quote and the other escapes are left to the pattern, such as r"\d+"
+func (p *Parser) parseRegexLiteral() ast.Expression {
+	literal := &ast.RegexLiteral{Token: p.curToken}
+	source := p.curToken.Literal
+	if len(source) < 3 || !strings.HasSuffix(source, "\"") {
+		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as regex: unterminated literal", source))
+		return nil
+	}
+	re, err := regexp.Compile(unescapeQuotes(source[2 : len(source)-1]))
+	if err != nil {
+		p.errors = append(p.errors, fmt.Sprintf("could not parse %q as regex: %s", source, err))
+		return nil
+	}
+	literal.Value = re
+	return literal
+}
+
+// unescapeQuotes function replaces the escaped quotes with quotes, and leaves the other escape sequences as they are
+func unescapeQuotes(source string) string {
+	var out strings.Builder
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\\' && i+1 < len(source) {
+			i++
+			if source[i] != '"' {
+				out.WriteByte('\\')
+			}
+		}
+		out.WriteByte(source[i])
+	}
+	return out.String()
+}
+
+// unescapeBytes function decodes the escape sequences of a bytes literal
+func unescapeBytes(source string) ([]byte, error) {
+	value := []byte{}
+	for i := 0; i < len(source); i++ {
+		if source[i] != '\\' {
+			value = append(value, source[i])
+			continue
+		}
+		i++
+		if len(source) <= i {
+			return nil, fmt.Errorf("trailing backslash")
+		}
+		switch source[i] {
+		case 'n':
+			value = append(value, '\n')
+		case 't':
+			value = append(value, '\t')
+		case 'r':
+			value = append(value, '\r')
+		case '0':
+			value = append(value, 0)
+		case '\\', '"':
+			value = append(value, source[i])
+		case 'x':
+			if len(source) < i+3 {
+				return nil, fmt.Errorf("short escape \\x%s", source[i+1:])
+			}
+			b, err := strconv.ParseUint(source[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid escape \\x%s", source[i+1:i+3])
+			}
+			value = append(value, byte(b))
+			i += 2
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c", source[i])
+		}
+	}
+	return value, nil
+}
+
 // prefix
 
 // registerPrefix method of Parser struct
@@ -285,6 +667,11 @@ func (p *Parser) parseBoolean() ast.Expression {
 	}
 }
 
+// parseNullLiteral method of Parser struct
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
 // parseStringLiteral method of Parser struct
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{
@@ -301,11 +688,11 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 		return list
 	}
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	list = append(list, p.parseListElement())
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+		list = append(list, p.parseListElement())
 	}
 	if !p.expectPeek(end) {
 		return nil
@@ -313,6 +700,23 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+// parseListElement method of Parser struct parses an element of an expression list, which can be spread
+func (p *Parser) parseListElement() ast.Expression {
+	if p.curTokenIs(token.ELLIPSIS) {
+		return p.parseSpreadExpression()
+	}
+	return p.parseExpression(LOWEST)
+}
+
+// parseSpreadExpression method of Parser struct
+// spread expression is expected to be "...<expression>"
+func (p *Parser) parseSpreadExpression() *ast.SpreadExpression {
+	spread := &ast.SpreadExpression{Token: p.curToken}
+	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
+	return spread
+}
+
 // parseArrayLiteral method of Parser struct
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	return &ast.ArrayLiteral{
@@ -321,34 +725,43 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	}
 }
 
+// parseSetLiteral method of Parser struct
+// set literal is expected to be "#{<element>, ...}"
+func (p *Parser) parseSetLiteral() ast.Expression {
+	return &ast.SetLiteral{
+		Token:    p.curToken,
+		Elements: p.parseExpressionList(token.RBRACE),
+	}
+}
+
 // parseHashLiteral method of Parser struct
 func (p *Parser) parseHashLiteral() ast.Expression {
-	hash := &ast.HashLiteral{
-		Token: p.curToken,
-		Pairs: make(map[ast.Expression]ast.Expression),
-	}
-	for !p.curTokenIs(token.RBRACE) {
-		// fmt.Println(p.curToken.Literal) // print
-		// key
+	hash := &ast.HashLiteral{Token: p.curToken}
+	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken() // go forward
-		// fmt.Println(p.curToken.Literal) // print
+		if p.curTokenIs(token.ELLIPSIS) {
+			hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Spread: p.parseSpreadExpression()})
+			if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+			continue
+		}
+
+		// key
 		key := p.parseExpression(LOWEST)
 		if !p.expectPeek(token.COLON) { // go forward
 			return nil
 		}
 
-		// fmt.Println("colon ", p.curToken.Literal) // print
 		// item
 		p.nextToken() // go forward
-		// fmt.Println(p.curToken.Literal) // print
 		item := p.parseExpression(LOWEST)
-		hash.Pairs[key] = item
-		if !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.COMMA) {
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: item})
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
-		p.nextToken()
 	}
-	if !p.curTokenIs(token.RBRACE) { // go forward
+	if !p.expectPeek(token.RBRACE) { // go forward
 		return nil
 	}
 	return hash
@@ -410,13 +823,34 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression { // e
 // grouped exression
 
 // parseGroupedExpression method of Parser srruct
+// "(<expression>, ...)" with a comma is a tuple, and "()" is the empty tuple
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tuple := &ast.TupleLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return tuple
+	}
 	p.nextToken()
 	exp := p.parseExpression(LOWEST) // recursive call
+	if !p.peekTokenIs(token.COMMA) {
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return exp
+	}
+	tuple.Elements = append(tuple.Elements, exp)
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) { // trailing comma
+			break
+		}
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	return exp
+	return tuple
 }
 
 // if expression
@@ -452,6 +886,50 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return exp
 }
 
+// try expression
+// try expression is expected to be "try <block> catch (<identifier>) <catch> finally <finally>;"
+// either catch part or finally part can be omitted, but not both of them
+
+// parseTryExpression method of Parser struct
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{
+		Token: p.curToken,
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	exp.Block = p.parseBlockStatement()
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		exp.CatchParam = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Catch = p.parseBlockStatement()
+	}
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Finally = p.parseBlockStatement()
+	}
+	if exp.Catch == nil && exp.Finally == nil {
+		p.errors = append(p.errors, "expected catch or finally after try block")
+		return nil
+	}
+	return exp
+}
+
 // function literal
 // function literal is expected to be "fn(<parameters>) <body>;"
 // <parameters> is []*ast.Identifier
@@ -465,14 +943,124 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
-	literal.Parameters = p.parseFunctionParameters()
-	if !p.expectPeek(token.LBRACE) {
+	if !p.parseFunctionSignature(literal) {
 		return nil
 	}
-	literal.Body = p.parseBlockStatement()
+	p.parseFunctionBody(literal)
 	return literal
 }
 
+// parseFunctionSignature method of Parser struct parses the parameters and the return type of the function literal
+// signature is expected to be "(<identifier>: <type>, <identifier>, ...) -> <type>", where every annotation is optional
+func (p *Parser) parseFunctionSignature(literal *ast.FunctionLiteral) bool {
+	literal.Parameters = []*ast.Identifier{}
+	literal.ParameterTypes = []ast.TypeAnnotation{}
+	for !p.peekTokenIs(token.RPAREN) {
+		if !p.expectPeek(token.IDENT) {
+			return false
+		}
+		literal.Parameters = append(literal.Parameters, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		var annotation ast.TypeAnnotation
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			annotation = p.parseTypeAnnotation()
+		}
+		literal.ParameterTypes = append(literal.ParameterTypes, annotation)
+		if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+			return false
+		}
+	}
+	p.nextToken() // skip ")"
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
+		p.nextToken()
+		literal.ReturnType = p.parseTypeAnnotation()
+	}
+	return p.expectPeek(token.LBRACE)
+}
+
+// parseTypeAnnotation method of Parser struct parses a type annotation starting at the current token
+// type annotation is expected to be "<name>", "[<type>]", "{<type>: <type>}" or "fn(<type>, ...) -> <type>"
+func (p *Parser) parseTypeAnnotation() ast.TypeAnnotation {
+	switch p.curToken.Type {
+	case token.IDENT, token.NULL:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+	case token.LBRACKET:
+		annotation := &ast.ArrayType{Token: p.curToken}
+		p.nextToken()
+		annotation.Element = p.parseTypeAnnotation()
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return annotation
+	case token.LPAREN:
+		annotation := &ast.TupleType{Token: p.curToken, Elements: []ast.TypeAnnotation{}}
+		for !p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+			annotation.Elements = append(annotation.Elements, p.parseTypeAnnotation())
+			if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+		}
+		p.nextToken() // skip ")"
+		return annotation
+	case token.HASHBRACE:
+		annotation := &ast.SetType{Token: p.curToken}
+		p.nextToken()
+		annotation.Element = p.parseTypeAnnotation()
+		if !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return annotation
+	case token.LBRACE:
+		annotation := &ast.HashType{Token: p.curToken}
+		p.nextToken()
+		annotation.Key = p.parseTypeAnnotation()
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		annotation.Value = p.parseTypeAnnotation()
+		if !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return annotation
+	case token.FUNCTION:
+		annotation := &ast.FunctionType{Token: p.curToken, Parameters: []ast.TypeAnnotation{}}
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		for !p.peekTokenIs(token.RPAREN) {
+			p.nextToken()
+			annotation.Parameters = append(annotation.Parameters, p.parseTypeAnnotation())
+			if !p.peekTokenIs(token.RPAREN) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+		}
+		p.nextToken() // skip ")"
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		annotation.Return = p.parseTypeAnnotation()
+		return annotation
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("expected type, got %s instead", p.curToken.Type))
+		return nil
+	}
+}
+
+// parseFunctionBody method of Parser struct parses the body of the function literal
+// the function is marked as a generator if yield appears in the body, except in nested functions
+func (p *Parser) parseFunctionBody(literal *ast.FunctionLiteral) {
+	outer := p.sawYield
+	p.sawYield = false
+	literal.Body = p.parseBlockStatement()
+	literal.IsGenerator = p.sawYield
+	p.sawYield = outer
+}
+
 // parseFunctionParameters method of Parser struct
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	params := []*ast.Identifier{}
@@ -542,8 +1130,9 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 // parseIndexExpression method of Parser struct
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{
-		Token: p.curToken,
-		Left:  left,
+		Token:    p.curToken,
+		Left:     left,
+		Optional: p.curTokenIs(token.QLBRACKET),
 	}
 	p.nextToken()
 	exp.Index = p.parseExpression(LOWEST)
@@ -553,6 +1142,52 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// parseDotExpression method of Parser struct
+// "<left>.<name>" is parsed as an index expression with the string key "<name>"
+// and "<left>?.<name>" as an optional one
+func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{
+		Token:    p.curToken,
+		Left:     left,
+		Optional: p.curTokenIs(token.QDOT),
+	}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Index = &ast.StringLiteral{
+		Token: token.Token{Type: token.STRING, Literal: p.curToken.Literal},
+		Value: p.curToken.Literal,
+	}
+	return exp
+}
+
+// parseUpdateExpression method of Parser struct
+// update expression is expected to be "<record> with { <field>: <expression>, ... }"
+func (p *Parser) parseUpdateExpression(record ast.Expression) ast.Expression {
+	exp := &ast.UpdateExpression{Token: p.curToken, Record: record}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		exp.Fields = append(exp.Fields, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		exp.Values = append(exp.Values, p.parseExpression(LOWEST))
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return exp
+}
+
 /* ====== assertion functions ====== */
 
 // peekTokenIs method of Parse, checking the type of current token
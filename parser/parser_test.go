@@ -202,6 +202,175 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890;"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statement does not contain 1 statements. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+	if !ok {
+		t.Fatalf("stmt not *ast.BigIntegerLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Value not %s. got %s", "123456789012345678901234567890", literal.Value)
+	}
+}
+
+func TestDecimalLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue string
+		expectedScale int
+	}{
+		{"12.50d", "1250", 2},
+		{"3d", "3", 0},
+		{"0.001d", "1", 3},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.DecimalLiteral)
+		if !ok {
+			t.Fatalf("stmt not *ast.DecimalLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value.String() != tt.expectedValue || literal.Scale != tt.expectedScale {
+			t.Errorf("literal not %se-%d. got %se-%d", tt.expectedValue, tt.expectedScale, literal.Value, literal.Scale)
+		}
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+		}
+	}
+}
+
+func TestBytesLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []byte
+	}{
+		{`b"abc"`, []byte("abc")},
+		{`b""`, []byte{}},
+		{`b"\x00\xffA\n\"\\"`, []byte{0, 0xff, 'A', '\n', '"', '\\'}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.BytesLiteral)
+		if !ok {
+			t.Fatalf("stmt not *ast.BytesLiteral. got=%T", stmt.Expression)
+		}
+		if string(literal.Value) != string(tt.expected) {
+			t.Errorf("literal.Value not %q. got=%q", tt.expected, literal.Value)
+		}
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+		}
+	}
+}
+
+func TestBytesLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`b"\x4"`, `could not parse "b\"\\x4\"" as bytes: short escape \x4`},
+		{`b"\xzz"`, `could not parse "b\"\\xzz\"" as bytes: invalid escape \xzz`},
+		{`b"\q"`, `could not parse "b\"\\q\"" as bytes: unknown escape \q`},
+		{`b"abc`, `could not parse "b\"abc" as bytes: unterminated literal`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong parser error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestRegexLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`r"\d+"`, `\d+`},
+		{`r""`, ``},
+		{`r"say \"(\w+)\"\\"`, `say "(\w+)"\\`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.RegexLiteral)
+		if !ok {
+			t.Fatalf("stmt not *ast.RegexLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value.String() != tt.expected {
+			t.Errorf("literal.Value not %q. got=%q", tt.expected, literal.Value.String())
+		}
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+		}
+	}
+}
+
+func TestRegexLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`r"(a"`, `could not parse "r\"(a\"" as regex: error parsing regexp: missing closing ): ` + "`(a`"},
+		{`r"abc`, `could not parse "r\"abc" as regex: unterminated literal`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong parser error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 // prefix expression
 // TestParsingPrefixExpressions function
 func TestParsingPrefixExpressions(t *testing.T) {
@@ -401,6 +570,58 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"1 + (2 + 3) + 4",
 			"((1 + (2 + 3)) + 4)",
 		},
+		{
+			"a ?? b == c",
+			"(a ?? (b == c))",
+		},
+		{
+			"a ?? b ?? c",
+			"((a ?? b) ?? c)",
+		},
+		{
+			"a?.b?[c] ?? d + 1",
+			"(a?.b?[c] ?? (d + 1))",
+		},
+		{
+			"a?.b[c]",
+			"a?.b[c]",
+		},
+		{
+			"-p.x * q.y",
+			"((-p.x) * q.y)",
+		},
+		{
+			"p with {x: 1}.x == 1",
+			"((p with {x: 1}).x == 1)",
+		},
+		{
+			"a.b with {x: y + 1}",
+			"(a.b with {x: (y + 1)})",
+		},
+		{
+			"a | b & c == d",
+			"((a | (b & c)) == d)",
+		},
+		{
+			"a - b | c & d + e",
+			"((a - b) | (c & (d + e)))",
+		},
+		{
+			"x == null",
+			"(x == null)",
+		},
+		{
+			"0..n + 1",
+			"(0 .. (n + 1))",
+		},
+		{
+			"a..=b == c..d",
+			"((a ..= b) == (c .. d))",
+		},
+		{
+			"xs[1..3]",
+			"xs[(1 .. 3)]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -836,7 +1057,8 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -846,6 +1068,29 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		expectedValue := expected[literal.String()]
 		testIntegerLiteral(t, value, expectedValue)
 	}
+
+	if hash.String() != "{one:1, two:2, three:3}" {
+		t.Errorf("pairs are not in source order. got=%q", hash.String())
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
 }
 
 func TestParsingHashLiteralsBooleanKeys(t *testing.T) {
@@ -871,7 +1116,8 @@ func TestParsingHashLiteralsBooleanKeys(t *testing.T) {
 		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		boolean, ok := key.(*ast.Boolean)
 		if !ok {
 			t.Errorf("key is not ast.BooleanLiteral. got=%T", key)
@@ -907,7 +1153,8 @@ func TestParsingHashLiteralsIntegerKeys(t *testing.T) {
 		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		integer, ok := key.(*ast.IntegerLiteral)
 		if !ok {
 			t.Errorf("key is not ast.IntegerLiteral. got=%T", key)
@@ -950,7 +1197,8 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -1013,3 +1261,673 @@ func TestMacroLiteralParsing(t *testing.T) {
 
 	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
+
+func TestThrowStatement(t *testing.T) {
+	input := `throw 5 + 5;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T", program.Statements[0])
+	}
+
+	testInfixExpression(t, stmt.Value, 5, "+", 5)
+}
+
+func TestTryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedParam   string
+		expectedCatch   bool
+		expectedFinally bool
+	}{
+		{"try { x } catch (e) { e }", "e", true, false},
+		{"try { x } finally { y }", "", false, true},
+		{"try { x } catch (err) { err } finally { y }", "err", true, true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+		}
+
+		if len(exp.Block.Statements) != 1 {
+			t.Errorf("try block is not 1 statements. got=%d\n", len(exp.Block.Statements))
+		}
+
+		if (exp.Catch != nil) != tt.expectedCatch {
+			t.Errorf("exp.Catch wrong. want present=%t, got=%+v", tt.expectedCatch, exp.Catch)
+		}
+		if exp.Catch != nil && !testIdentifier(t, exp.CatchParam, tt.expectedParam) {
+			return
+		}
+
+		if (exp.Finally != nil) != tt.expectedFinally {
+			t.Errorf("exp.Finally wrong. want present=%t, got=%+v", tt.expectedFinally, exp.Finally)
+		}
+	}
+}
+
+func TestTryExpressionWithoutHandler(t *testing.T) {
+	l := lexer.New("try { x }")
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected a parser error for try without catch or finally")
+	}
+}
+
+func TestDeferStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"defer close(file);", "defer close(file);"},
+		{"defer fn() { x }();", "defer fn()x();"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.DeferStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.DeferStatement. got=%T", program.Statements[0])
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. want=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestDeferStatementRequiresCall(t *testing.T) {
+	l := lexer.New("defer x;")
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected a parser error for defer without function call")
+	}
+}
+
+func TestFunctionStatementParsing(t *testing.T) {
+	input := `fn add(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+
+	if !testIdentifier(t, stmt.Name, "add") {
+		return
+	}
+	if stmt.Function.Name != "add" {
+		t.Errorf("function name wrong. want=%q, got=%q", "add", stmt.Function.Name)
+	}
+
+	if len(stmt.Function.Parameters) != 2 {
+		t.Fatalf("function parameters wrong. want 2, got=%d\n", len(stmt.Function.Parameters))
+	}
+	testLiteralExpression(t, stmt.Function.Parameters[0], "x")
+	testLiteralExpression(t, stmt.Function.Parameters[1], "y")
+
+	if stmt.String() != "fn add(x, y)(x + y)" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestFunctionLiteralWithName(t *testing.T) {
+	input := `let myFunction = fn() { };`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+
+	if function.Name != "myFunction" {
+		t.Errorf("function literal name wrong. want=%q, got=%q", "myFunction", function.Name)
+	}
+}
+
+func TestOptionalIndexExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedLeft  string
+		expectedIndex string
+	}{
+		{"config?.port", "config", "port"},
+		{"config?[key]", "config", "key"},
+		{"a?.b?.c", "a?.b", "c"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.IndexExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.IndexExpression. got=%T", stmt.Expression)
+		}
+		if !exp.Optional {
+			t.Errorf("exp.Optional is not true for %q", tt.input)
+		}
+		if exp.Left.String() != tt.expectedLeft {
+			t.Errorf("exp.Left wrong. want=%q, got=%q", tt.expectedLeft, exp.Left.String())
+		}
+		if exp.Index.String() != tt.expectedIndex {
+			t.Errorf("exp.Index wrong. want=%q, got=%q", tt.expectedIndex, exp.Index.String())
+		}
+	}
+}
+
+func TestNullLiteralParsing(t *testing.T) {
+	l := lexer.New("null;")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	if _, ok := stmt.Expression.(*ast.NullLiteral); !ok {
+		t.Fatalf("stmt.Expression is not ast.NullLiteral. got=%T", stmt.Expression)
+	}
+}
+
+func TestSpreadExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f(...args)", "f(...args)"},
+		{"f(a, ...b, c)", "f(a,...b,c)"},
+		{"[...a, ...b]", "[...a, ...b]"},
+		{"[0, ...rest(xs)]", "[0, ...rest(xs)]"},
+		{"{...defaults}", "{...defaults}"},
+		{"{...defaults, ...overrides}", "{...defaults, ...overrides}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestTupleParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(1, 2)", "(1, 2)"},
+		{"(a + b, c * d, f(x))", "((a + b), (c * d), f(x))"},
+		{"(1,)", "(1,)"},
+		{"(1, 2,)", "(1, 2)"},
+		{"()", "()"},
+		{"(1)", "1"},
+		{"((1, 2), 3)[0]", "((1, 2), 3)[0]"},
+		{"return a, b;", "return (a, b);"},
+		{"return (a, b);", "return (a, b);"},
+		{"let (a, b) = f(x);", "let (a, b) = f(x);"},
+		{"let (value) = t", "let (value) = t;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestUnpackStatement(t *testing.T) {
+	l := lexer.New("let (x, y) = (1, 2);")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.UnpackStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.UnpackStatement. got=%T", program.Statements[0])
+	}
+	if len(stmt.Names) != 2 || stmt.Names[0].Value != "x" || stmt.Names[1].Value != "y" {
+		t.Errorf("wrong names. got=%v", stmt.Names)
+	}
+	tuple, ok := stmt.Value.(*ast.TupleLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value not *ast.TupleLiteral. got=%T", stmt.Value)
+	}
+	testIntegerLiteral(t, tuple.Elements[0], 1)
+	testIntegerLiteral(t, tuple.Elements[1], 2)
+}
+
+func TestUnpackStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let (x, x) = t;", "duplicate name x in let"},
+		{"let () = t;", "expected names to unpack in let"},
+		{"let (x, 1) = t;", "expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong parser error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestParsingSetLiterals(t *testing.T) {
+	input := "#{1, 2 * 2, ...xs}"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	set, ok := stmt.Expression.(*ast.SetLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.SetLiteral. got=%T", stmt.Expression)
+	}
+	if len(set.Elements) != 3 {
+		t.Fatalf("len(set.Elements) not 3. got=%d", len(set.Elements))
+	}
+	testIntegerLiteral(t, set.Elements[0], 1)
+	testInfixExpression(t, set.Elements[1], 2, "*", 2)
+	if _, ok := set.Elements[2].(*ast.SpreadExpression); !ok {
+		t.Errorf("set.Elements[2] not *ast.SpreadExpression. got=%T", set.Elements[2])
+	}
+	if set.String() != "#{1, (2 * 2), ...xs}" {
+		t.Errorf("set.String() wrong. got=%q", set.String())
+	}
+
+	l = lexer.New("#{}")
+	p = New(l)
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	set, ok = program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SetLiteral)
+	if !ok || len(set.Elements) != 0 {
+		t.Errorf("empty set literal not parsed. got=%q", program.String())
+	}
+}
+
+func TestParsingHashLiteralsWithSpreads(t *testing.T) {
+	input := `{...defaults, "one": 1, ...overrides}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	if hash.Pairs[0].Spread == nil || hash.Pairs[1].Spread != nil || hash.Pairs[2].Spread == nil {
+		t.Fatalf("spreads are not kept in source order. got=%q", hash.String())
+	}
+	testIdentifier(t, hash.Pairs[0].Spread.Value, "defaults")
+	if key, ok := hash.Pairs[1].Key.(*ast.StringLiteral); !ok || key.Value != "one" {
+		t.Errorf("key is not ast.StringLiteral one. got=%q", hash.Pairs[1].Key)
+	}
+	testIntegerLiteral(t, hash.Pairs[1].Value, 1)
+	testIdentifier(t, hash.Pairs[2].Spread.Value, "overrides")
+
+	if hash.String() != `{...defaults, one:1, ...overrides}` {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
+func TestGeneratorFunctionParsing(t *testing.T) {
+	tests := []struct {
+		input       string
+		isGenerator bool
+	}{
+		{"fn() { yield 1; }", true},
+		{"fn(x) { if (x) { yield x; } }", true},
+		{"fn() { 1 }", false},
+		{"fn() { fn() { yield 1; } }", false},
+		{"fn() { let f = fn() { yield 1; }; yield 2; }", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+		if function.IsGenerator != tt.isGenerator {
+			t.Errorf("function.IsGenerator wrong for %q. want=%t, got=%t", tt.input, tt.isGenerator, function.IsGenerator)
+		}
+	}
+
+	l := lexer.New("fn gen() { yield 1; }")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.FunctionStatement)
+	if !stmt.Function.IsGenerator {
+		t.Errorf("function declaration is not marked as generator")
+	}
+}
+
+func TestYieldStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		delegate bool
+		expected string
+	}{
+		{"yield x;", false, "yield x;"},
+		{"yield ...gen(n + 1);", true, "yield ...gen((n + 1));"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.YieldStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.YieldStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Delegate != tt.delegate {
+			t.Errorf("stmt.Delegate wrong. want=%t, got=%t", tt.delegate, stmt.Delegate)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. want=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestStructStatement(t *testing.T) {
+	input := "struct Point { x, y }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.StructStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Name.Value != "Point" {
+		t.Errorf("stmt.Name wrong. want=%q, got=%q", "Point", stmt.Name.Value)
+	}
+	if len(stmt.Fields) != 2 {
+		t.Fatalf("stmt.Fields has wrong length. got=%d", len(stmt.Fields))
+	}
+	testIdentifier(t, stmt.Fields[0], "x")
+	testIdentifier(t, stmt.Fields[1], "y")
+	if stmt.String() != input {
+		t.Errorf("stmt.String() wrong. want=%q, got=%q", input, stmt.String())
+	}
+}
+
+func TestStructStatementErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"struct Point { x, x }", "duplicate field x in struct Point"},
+		{"struct Point { 1 }", "expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestDotExpressionParsing(t *testing.T) {
+	l := lexer.New("point.x")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IndexExpression. got=%T", stmt.Expression)
+	}
+	if exp.Optional {
+		t.Errorf("exp.Optional is true")
+	}
+	testIdentifier(t, exp.Left, "point")
+	index, ok := exp.Index.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp.Index is not ast.StringLiteral. got=%T", exp.Index)
+	}
+	if index.Value != "x" {
+		t.Errorf("index.Value wrong. want=%q, got=%q", "x", index.Value)
+	}
+}
+
+func TestUpdateExpressionParsing(t *testing.T) {
+	l := lexer.New("p with { x: 1, y: a * 2 }")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.UpdateExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.UpdateExpression. got=%T", stmt.Expression)
+	}
+	testIdentifier(t, exp.Record, "p")
+	if len(exp.Fields) != 2 || len(exp.Values) != 2 {
+		t.Fatalf("wrong number of fields. got=%d, %d", len(exp.Fields), len(exp.Values))
+	}
+	testIdentifier(t, exp.Fields[0], "x")
+	testIntegerLiteral(t, exp.Values[0], 1)
+	testIdentifier(t, exp.Fields[1], "y")
+	testInfixExpression(t, exp.Values[1], "a", "*", 2)
+}
+
+func TestEnumStatement(t *testing.T) {
+	input := "enum Shape { Empty, Circle(r), Rect(w, h) }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.EnumStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.EnumStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Name.Value != "Shape" {
+		t.Errorf("stmt.Name wrong. want=%q, got=%q", "Shape", stmt.Name.Value)
+	}
+
+	expected := []struct {
+		name   string
+		fields []string
+	}{
+		{"Empty", []string{}},
+		{"Circle", []string{"r"}},
+		{"Rect", []string{"w", "h"}},
+	}
+	if len(stmt.Variants) != len(expected) {
+		t.Fatalf("stmt.Variants has wrong length. got=%d", len(stmt.Variants))
+	}
+	for i, variant := range expected {
+		testIdentifier(t, stmt.Variants[i].Name, variant.name)
+		if len(stmt.Variants[i].Fields) != len(variant.fields) {
+			t.Fatalf("variant %s has wrong number of fields. got=%d", variant.name, len(stmt.Variants[i].Fields))
+		}
+		for j, field := range variant.fields {
+			testIdentifier(t, stmt.Variants[i].Fields[j], field)
+		}
+	}
+	if stmt.String() != input {
+		t.Errorf("stmt.String() wrong. want=%q, got=%q", input, stmt.String())
+	}
+
+	p = New(lexer.New("enum Shape { Empty, Empty }"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "duplicate variant Empty in enum Shape" {
+		t.Errorf("wrong errors for duplicate variants. got=%v", p.Errors())
+	}
+}
+
+func TestTypeAnnotationParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 5;", "let x: int = 5;"},
+		{"let xs: [string] = [];", "let xs: [string] = [];"},
+		{"let h: {string: [int]} = {};", "let h: {string: [int]} = {};"},
+		{"let f: fn(int, bool) -> null = g;", "let f: fn(int, bool) -> null = g;"},
+		{"let f: fn() -> fn(int) -> int = g;", "let f: fn() -> fn(int) -> int = g;"},
+		{"let p: Point = q;", "let p: Point = q;"},
+		{"let s: #{string} = t;", "let s: #{string} = t;"},
+		{"let t: (int, [string]) = u;", "let t: (int, [string]) = u;"},
+		{"fn() -> (int, bool) { (1, true) }", "fn() -> (int, bool)(1, true)"},
+		{"fn(x: int, y: string) -> bool { true }", "fn(x: int, y: string) -> booltrue"},
+		{"fn(x, y: int) { x }", "fn(x, y: int)x"},
+		{"fn() -> [int] { [] }", "fn() -> [int][]"},
+		{"fn add(x: int, y: int) -> int { x + y }", "fn add(x: int, y: int) -> int(x + y)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestFunctionSignatureAnnotations(t *testing.T) {
+	l := lexer.New("fn(x: int, y) -> [bool] { x }")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if len(function.ParameterTypes) != 2 {
+		t.Fatalf("function.ParameterTypes has wrong length. got=%d", len(function.ParameterTypes))
+	}
+	named, ok := function.ParameterTypes[0].(*ast.NamedType)
+	if !ok || named.Name != "int" {
+		t.Errorf("function.ParameterTypes[0] is not int. got=%#v", function.ParameterTypes[0])
+	}
+	if function.ParameterTypes[1] != nil {
+		t.Errorf("function.ParameterTypes[1] is not nil. got=%#v", function.ParameterTypes[1])
+	}
+	array, ok := function.ReturnType.(*ast.ArrayType)
+	if !ok {
+		t.Fatalf("function.ReturnType is not ast.ArrayType. got=%T", function.ReturnType)
+	}
+	if array.Element.String() != "bool" {
+		t.Errorf("array.Element wrong. got=%q", array.Element.String())
+	}
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x: 1 = 1;", "expected type, got INT instead"},
+		{"let f: fn(int) = g;", "expected next token to be ->, got = instead"},
+		{"fn(x: [int) { x }", "expected next token to be ], got ) instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("no parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("wrong error for %q. want=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
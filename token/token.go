@@ -16,15 +16,20 @@ const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT   = "IDENT"
+	INT     = "INT"
+	DECIMAL = "DECIMAL" // 12.50d
+	BYTES   = "BYTES"   // b"\x00"
+	REGEX   = "REGEX"   // r"\d+"
 
-	ASSIGN   = "="
-	PLUS     = "+"
-	MINUS    = "-"
-	ASTERISK = "*"
-	SLASH    = "/"
-	BANG     = "!"
+	ASSIGN    = "="
+	PLUS      = "+"
+	MINUS     = "-"
+	ASTERISK  = "*"
+	SLASH     = "/"
+	PIPE      = "|"
+	AMPERSAND = "&"
+	BANG      = "!"
 
 	LT  = "<"
 	GT  = ">"
@@ -33,6 +38,17 @@ const (
 	EQ  = "=="
 	NEQ = "!="
 
+	QDOT      = "?."
+	QLBRACKET = "?["
+	NULLISH   = "??"
+
+	ARROW = "->"
+
+	DOT      = "."
+	ELLIPSIS = "..."
+	DOTDOT   = ".."
+	DOTDOTEQ = "..="
+
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
@@ -40,8 +56,9 @@ const (
 	LPAREN = "("
 	RPAREN = ")"
 
-	LBRACE = "{"
-	RBRACE = "}"
+	LBRACE    = "{"
+	HASHBRACE = "#{"
+	RBRACE    = "}"
 
 	LBRACKET = "["
 	RBRACKET = "]"
@@ -50,28 +67,48 @@ const (
 	LET      = "LET"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	NULL     = "NULL"
 	IF       = "IF"
 	THEN     = "THEN"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	FOR      = "FOR"
 	MACRO    = "MACRO"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	DEFER    = "DEFER"
+	YIELD    = "YIELD"
+	STRUCT   = "STRUCT"
+	ENUM     = "ENUM"
+	WITH     = "WITH"
 
 	STRING = "STRING"
 )
 
 // token.keywords is a map which contains the reserved identifier
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"then":   THEN,
-	"else":   ELSE,
-	"return": RETURN,
-	"for":    FOR,
-	"macro":  MACRO,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"true":    TRUE,
+	"false":   FALSE,
+	"null":    NULL,
+	"if":      IF,
+	"then":    THEN,
+	"else":    ELSE,
+	"return":  RETURN,
+	"for":     FOR,
+	"macro":   MACRO,
+	"throw":   THROW,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"defer":   DEFER,
+	"yield":   YIELD,
+	"struct":  STRUCT,
+	"enum":    ENUM,
+	"with":    WITH,
 }
 
 // LookupIdent function identify whether the identifier is keyword or not, and return its token type
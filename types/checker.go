@@ -0,0 +1,833 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/BOBO1997/monkey/ast"
+)
+
+// Check function type-checks the program without evaluating it, and returns the type errors
+/*
+	The checking is gradual: unannotated parameters are of any, and the types of the other unannotated names are inferred.
+	An expression of any is never reported, so that unannotated code is checked only as far as its types are known.
+*/
+func Check(program *ast.Program) []string {
+	c := &checker{scope: newScope(universe(), 0)}
+	c.checkStatements(program.Statements)
+	return c.errors
+}
+
+// checker struct holds the state of a type checking
+type checker struct {
+	errors    []string
+	scope     *scope
+	depth     int      // number of the function literals enclosing the current node
+	functions []*frame // the function literals being checked, innermost last
+}
+
+// frame struct collects the types returned by a function literal
+type frame struct {
+	name    string
+	returns []Type
+	result  Type // annotated return type, nil if not annotated
+}
+
+// binding struct is a name defined in a scope
+// a binding declared in advance is not defined until its let statement is checked
+type binding struct {
+	typ     Type
+	defined bool
+}
+
+// scope struct holds the names of values and of types defined in a block
+type scope struct {
+	values map[string]*binding
+	types  map[string]Type
+	depth  int // depth of the checker when the scope was created
+	outer  *scope
+}
+
+// newScope function
+func newScope(outer *scope, depth int) *scope {
+	return &scope{
+		values: make(map[string]*binding),
+		types:  make(map[string]Type),
+		depth:  depth,
+		outer:  outer,
+	}
+}
+
+// universe function creates the scope of the builtin functions
+func universe() *scope {
+	s := newScope(nil, 0)
+	for name, typ := range builtins {
+		s.values[name] = &binding{typ: typ, defined: true}
+	}
+	return s
+}
+
+// builtins holds the types of the builtin functions of the evaluator
+var builtins = map[string]Type{
+	"len":             &Function{Parameters: []Type{Any}, Return: Int},
+	"first":           &Function{Parameters: []Type{Any}, Return: Any},
+	"last":            &Function{Parameters: []Type{Any}, Return: Any},
+	"rest":            &Function{Parameters: []Type{Any}, Return: Any},
+	"push":            &Function{Parameters: []Type{Any, Any}, Return: Any},
+	"assoc":           &Function{Parameters: []Type{Any, Any, Any}, Return: Any},
+	"array":           &Function{Parameters: []Type{Any}, Return: &Array{Element: Any}},
+	"set":             &Function{Parameters: []Type{Any}, Return: &Set{Element: Any}},
+	"is":              &Function{Parameters: []Type{Any, Any}, Return: Bool},
+	"puts":            &Function{Variadic: true, Return: Null},
+	"__inspect_env__": &Function{Variadic: true, Return: Null},
+	"iter":            &Function{Parameters: []Type{Any}, Return: Any},
+	"next":            &Function{Variadic: true, Return: Any},
+	"close":           &Function{Parameters: []Type{Any}, Return: Null},
+	"take":            &Function{Parameters: []Type{Any, Int}, Return: Any},
+	"map":             &Function{Parameters: []Type{Any, Any}, Return: Any},
+	"filter":          &Function{Parameters: []Type{Any, Any}, Return: Any},
+	"each":            &Function{Parameters: []Type{Any, Any}, Return: Null},
+	"reduce":          &Function{Parameters: []Type{Any, Any, Any}, Return: Any},
+	"unquote":         &Function{Parameters: []Type{Any}, Return: Any},
+	"decimal":         &Function{Parameters: []Type{Any}, Return: Decimal},
+	"round":           &Function{Variadic: true, Return: Decimal},
+	"bytes":           &Function{Parameters: []Type{Any}, Return: Bytes},
+	"encode":          &Function{Parameters: []Type{String, String}, Return: Bytes},
+	"decode":          &Function{Parameters: []Type{Bytes, String}, Return: String},
+	"hex":             &Function{Parameters: []Type{Bytes}, Return: String},
+	"unhex":           &Function{Parameters: []Type{String}, Return: Bytes},
+	"base64":          &Function{Parameters: []Type{Bytes}, Return: String},
+	"unbase64":        &Function{Parameters: []Type{String}, Return: Bytes},
+	"bytelen":         &Function{Parameters: []Type{String}, Return: Int},
+	"graphemes":       &Function{Parameters: []Type{String}, Return: &Array{Element: String}},
+	"now":             &Function{Parameters: []Type{}, Return: Time},
+	"time":            &Function{Variadic: true, Return: Time},
+	"duration":        &Function{Parameters: []Type{Any}, Return: Duration},
+	"format":          &Function{Parameters: []Type{Time, String}, Return: String},
+	"timezone":        &Function{Parameters: []Type{Time, String}, Return: Time},
+	"regex":           &Function{Parameters: []Type{Any}, Return: Regex},
+	"match":           &Function{Parameters: []Type{Any, String}, Return: Bool},
+	"find":            &Function{Parameters: []Type{Any, String}, Return: Any},
+	"findall":         &Function{Parameters: []Type{Any, String}, Return: &Array{Element: String}},
+	"captures":        &Function{Parameters: []Type{Any, String}, Return: Any},
+	"replace":         &Function{Parameters: []Type{Any, String, Any}, Return: String},
+	"split":           &Function{Parameters: []Type{Any, String}, Return: &Array{Element: String}},
+	"json_parse":      &Function{Parameters: []Type{String}, Return: Any},
+	"json_stringify":  &Function{Variadic: true, Return: String},
+	"csv_parse":       &Function{Variadic: true, Return: &Array{Element: Any}},
+	"csv_open":        &Function{Variadic: true, Return: Any},
+	"csv_format":      &Function{Variadic: true, Return: String},
+}
+
+// errorf method of checker struct records a type error
+func (c *checker) errorf(format string, a ...interface{}) {
+	c.errors = append(c.errors, fmt.Sprintf(format, a...))
+}
+
+// enter method of checker struct opens a new scope
+func (c *checker) enter() {
+	c.scope = newScope(c.scope, c.depth)
+}
+
+// leave method of checker struct closes the current scope
+func (c *checker) leave() {
+	c.scope = c.scope.outer
+}
+
+// define method of checker struct defines the name in the current scope
+func (c *checker) define(name string, typ Type) {
+	c.scope.values[name] = &binding{typ: typ, defined: true}
+}
+
+// lookup method of checker struct returns the type of the name
+/*
+	A name declared later in an enclosing block can be used inside a function literal, since it may be defined when the function is called.
+	It is of any there. Elsewhere the name is not defined yet, so the lookup goes on to the outer scopes as the evaluator does.
+*/
+func (c *checker) lookup(name string) Type {
+	for s := c.scope; s != nil; s = s.outer {
+		b, ok := s.values[name]
+		if !ok {
+			continue
+		}
+		if b.defined {
+			return b.typ
+		}
+		if s.depth < c.depth {
+			return Any
+		}
+	}
+	c.errorf("identifier not found: %s", name)
+	return Any
+}
+
+// lookupType method of checker struct returns the struct or enum type of the name
+func (c *checker) lookupType(name string) (Type, bool) {
+	for s := c.scope; s != nil; s = s.outer {
+		if typ, ok := s.types[name]; ok {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+/* ====== statements ====== */
+
+// checkStatements method of checker struct checks the statements of a program or a block, and returns the type of the last one
+// function declarations and let statements are declared in advance, as the evaluator hoists function declarations
+// and the functions can refer to the names defined after them
+func (c *checker) checkStatements(stmts []ast.Statement) Type {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			if _, ok := c.scope.values[stmt.Name.Value]; !ok {
+				c.scope.values[stmt.Name.Value] = &binding{typ: Any}
+			}
+		case *ast.UnpackStatement:
+			for _, name := range stmt.Names {
+				if _, ok := c.scope.values[name.Value]; !ok {
+					c.scope.values[name.Value] = &binding{typ: Any}
+				}
+			}
+		case *ast.FunctionStatement:
+			c.define(stmt.Name.Value, c.signature(stmt.Function))
+		}
+	}
+	var result Type = Null
+	for _, stmt := range stmts {
+		result = c.checkStatement(stmt)
+	}
+	return result
+}
+
+// checkStatement method of checker struct
+func (c *checker) checkStatement(stmt ast.Statement) Type {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return c.checkExpression(stmt.Expression)
+	case *ast.LetStatement:
+		typ := c.checkExpression(stmt.Value)
+		if stmt.Type != nil {
+			declared := c.resolve(stmt.Type)
+			if !assignable(typ, declared) {
+				c.errorf("cannot use %s as %s in let %s", typ, declared, stmt.Name.Value)
+			}
+			typ = declared
+		}
+		c.define(stmt.Name.Value, typ)
+		return Any
+	case *ast.UnpackStatement:
+		c.checkUnpackStatement(stmt)
+		return Any
+	case *ast.ReturnStatement:
+		typ := c.checkExpression(stmt.ReturnValue)
+		if len(c.functions) > 0 {
+			f := c.functions[len(c.functions)-1]
+			f.returns = append(f.returns, typ)
+		}
+		return typ
+	case *ast.ThrowStatement:
+		c.checkExpression(stmt.Value)
+		return Any
+	case *ast.YieldStatement:
+		c.checkExpression(stmt.Value)
+		return Null
+	case *ast.DeferStatement:
+		c.checkExpression(stmt.Call)
+		return Null
+	case *ast.FunctionStatement:
+		c.define(stmt.Name.Value, c.checkFunctionLiteral(stmt.Function))
+		return Null
+	case *ast.StructStatement:
+		fields := []string{}
+		params := []Type{}
+		for _, field := range stmt.Fields {
+			fields = append(fields, field.Value)
+			params = append(params, Any)
+		}
+		typ := &Struct{Name: stmt.Name.Value, Fields: fields}
+		c.scope.types[typ.Name] = typ
+		c.define(typ.Name, &Function{Parameters: params, Return: typ})
+		return Any
+	case *ast.EnumStatement:
+		c.scope.types[stmt.Name.Value] = &Enum{Name: stmt.Name.Value}
+		c.define(stmt.Name.Value, Any)
+		return Any
+	}
+	return Any
+}
+
+// checkBlockStatement method of checker struct checks the block in a new scope
+func (c *checker) checkBlockStatement(block *ast.BlockStatement) Type {
+	c.enter()
+	defer c.leave()
+	return c.checkStatements(block.Statements)
+}
+
+// checkFunctionLiteral method of checker struct checks the body of the function, and returns the type of the function
+// the return type is inferred from the body unless annotated
+func (c *checker) checkFunctionLiteral(fl *ast.FunctionLiteral) Type {
+	typ := c.signature(fl)
+	f := &frame{name: fl.Name}
+	if fl.ReturnType != nil {
+		f.result = typ.Return
+	}
+
+	c.depth++
+	c.functions = append(c.functions, f)
+	c.enter()
+	for i, param := range fl.Parameters {
+		c.define(param.Value, typ.Parameters[i])
+	}
+	last := c.checkStatements(fl.Body.Statements)
+	c.leave()
+	c.functions = c.functions[:len(c.functions)-1]
+	c.depth--
+
+	if n := len(fl.Body.Statements); n == 0 {
+		f.returns = append(f.returns, Null)
+	} else if _, ok := fl.Body.Statements[n-1].(*ast.ReturnStatement); !ok {
+		f.returns = append(f.returns, last)
+	}
+	if fl.IsGenerator { // calling a generator function creates a generator, whatever the body returns
+		typ.Return = Any
+		return typ
+	}
+
+	if f.result != nil {
+		for _, returned := range f.returns {
+			if !assignable(returned, f.result) {
+				c.errorf("cannot use %s as %s in return of %s", returned, f.result, functionName(f.name))
+			}
+		}
+		return typ
+	}
+	typ.Return = f.returns[0]
+	for _, returned := range f.returns[1:] {
+		typ.Return = join(typ.Return, returned)
+	}
+	return typ
+}
+
+// signature method of checker struct returns the type of the function by its annotations
+func (c *checker) signature(fl *ast.FunctionLiteral) *Function {
+	typ := &Function{Return: Any}
+	for i := range fl.Parameters {
+		var param Type = Any
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			param = c.resolve(fl.ParameterTypes[i])
+		}
+		typ.Parameters = append(typ.Parameters, param)
+	}
+	if fl.ReturnType != nil {
+		typ.Return = c.resolve(fl.ReturnType)
+	}
+	return typ
+}
+
+// resolve method of checker struct returns the type denoted by the annotation
+func (c *checker) resolve(annotation ast.TypeAnnotation) Type {
+	switch annotation := annotation.(type) {
+	case *ast.NamedType:
+		switch annotation.Name {
+		case "int":
+			return Int
+		case "decimal":
+			return Decimal
+		case "string":
+			return String
+		case "bytes":
+			return Bytes
+		case "time":
+			return Time
+		case "duration":
+			return Duration
+		case "regex":
+			return Regex
+		case "bool":
+			return Bool
+		case "null":
+			return Null
+		case "range":
+			return Range
+		case "any":
+			return Any
+		}
+		if typ, ok := c.lookupType(annotation.Name); ok {
+			return typ
+		}
+		c.errorf("unknown type: %s", annotation.Name)
+		return Any
+	case *ast.ArrayType:
+		return &Array{Element: c.resolve(annotation.Element)}
+	case *ast.SetType:
+		return &Set{Element: c.resolve(annotation.Element)}
+	case *ast.TupleType:
+		elements := []Type{}
+		for _, element := range annotation.Elements {
+			elements = append(elements, c.resolve(element))
+		}
+		return &Tuple{Elements: elements}
+	case *ast.HashType:
+		return &Hash{Key: c.resolve(annotation.Key), Value: c.resolve(annotation.Value)}
+	case *ast.FunctionType:
+		typ := &Function{Return: c.resolve(annotation.Return)}
+		for _, param := range annotation.Parameters {
+			typ.Parameters = append(typ.Parameters, c.resolve(param))
+		}
+		return typ
+	}
+	return Any
+}
+
+/* ====== expressions ====== */
+
+// checkExpression method of checker struct returns the type of the expression
+func (c *checker) checkExpression(exp ast.Expression) Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral:
+		return Int
+	case *ast.DecimalLiteral:
+		return Decimal
+	case *ast.StringLiteral:
+		return String
+	case *ast.BytesLiteral:
+		return Bytes
+	case *ast.RegexLiteral:
+		return Regex
+	case *ast.Boolean:
+		return Bool
+	case *ast.NullLiteral:
+		return Null
+	case *ast.Identifier:
+		return c.lookup(exp.Value)
+	case *ast.PrefixExpression:
+		return c.checkPrefixExpression(exp.Operator, c.checkExpression(exp.Right))
+	case *ast.InfixExpression:
+		left := c.checkExpression(exp.Left)
+		right := c.checkExpression(exp.Right)
+		return c.checkInfixExpression(exp.Operator, left, right)
+	case *ast.IfExpression:
+		c.checkExpression(exp.Condition)
+		consequence := c.checkBlockStatement(exp.Consequence)
+		if exp.Alternative == nil {
+			return join(consequence, Null)
+		}
+		return join(consequence, c.checkBlockStatement(exp.Alternative))
+	case *ast.TryExpression:
+		typ := c.checkBlockStatement(exp.Block)
+		if exp.Catch != nil {
+			c.enter()
+			c.define(exp.CatchParam.Value, Any)
+			typ = join(typ, c.checkBlockStatement(exp.Catch))
+			c.leave()
+		}
+		if exp.Finally != nil {
+			c.checkBlockStatement(exp.Finally)
+		}
+		return typ
+	case *ast.FunctionLiteral:
+		return c.checkFunctionLiteral(exp)
+	case *ast.CallExpression:
+		return c.checkCallExpression(exp)
+	case *ast.ArrayLiteral:
+		return &Array{Element: c.checkElements(exp.Elements)}
+	case *ast.SetLiteral:
+		return &Set{Element: c.checkElements(exp.Elements)}
+	case *ast.TupleLiteral:
+		elements := []Type{}
+		for _, element := range exp.Elements {
+			elements = append(elements, c.checkExpression(element))
+		}
+		return &Tuple{Elements: elements}
+	case *ast.HashLiteral:
+		return c.checkHashLiteral(exp)
+	case *ast.IndexExpression:
+		return c.checkIndexExpression(exp)
+	case *ast.UpdateExpression:
+		return c.checkUpdateExpression(exp)
+	case *ast.SpreadExpression:
+		c.checkExpression(exp.Value)
+		return Any
+	}
+	return Any // macros are expanded before checking, and anything else is left unchecked
+}
+
+// checkPrefixExpression method of checker struct
+func (c *checker) checkPrefixExpression(operator string, right Type) Type {
+	switch {
+	case operator == "!":
+		return Bool
+	case right == Any:
+		return Any
+	case operator == "-" && (right == Int || right == Decimal || right == Duration):
+		return right
+	default:
+		c.errorf("unknown operator: %s%s", operator, right)
+		return Any
+	}
+}
+
+// checkInfixExpression method of checker struct
+// the operators are checked as evalInfixExpression of the evaluator applies them
+func (c *checker) checkInfixExpression(operator string, left, right Type) Type {
+	switch operator {
+	case "==", "!=":
+		return Bool
+	case "??":
+		if left == Null {
+			return right
+		}
+		return join(left, right)
+	}
+	if left == Any || right == Any { // the result is decided by the other operand, if the operator succeeds
+		known := left
+		if known == Any {
+			known = right
+		}
+		switch {
+		case operator == "<" || operator == ">" || operator == "<=" || operator == ">=":
+			return Bool
+		case operator == ".." || operator == "..=":
+			return Range
+		case known == Decimal || ((known == String || known == Bytes) && operator == "+"):
+			return known
+		}
+		return Any // int + any may be a decimal
+	}
+	if left == Int && right == Int {
+		switch operator {
+		case "+", "-", "*", "/":
+			return Int
+		case "<", ">", "<=", ">=":
+			return Bool
+		case "..", "..=":
+			return Range
+		}
+	}
+	if (left == Decimal || left == Int) && (right == Decimal || right == Int) { // one of them is a decimal
+		switch operator {
+		case "+", "-", "*", "/":
+			return Decimal
+		case "<", ">", "<=", ">=":
+			return Bool
+		}
+	}
+	if typ, ok := checkTimeInfixExpression(operator, left, right); ok {
+		return typ
+	}
+	if leftSet, ok := left.(*Set); ok {
+		if rightSet, ok := right.(*Set); ok {
+			switch {
+			case operator == "-": // removing the elements of any set leaves those of the left
+				return leftSet
+			case operator == "&" || (operator == "|" && assignable(leftSet, rightSet)):
+				return &Set{Element: join(leftSet.Element, rightSet.Element)}
+			}
+		}
+	}
+	if (left == String || left == Bytes) && left == right && operator == "+" {
+		return left
+	}
+	if isSequence(left) && identical(left, right) {
+		switch operator {
+		case "<", ">", "<=", ">=":
+			return Bool
+		}
+	}
+	if !identical(left, right) {
+		c.errorf("type mismatch: %s %s %s", left, operator, right)
+	} else {
+		c.errorf("unknown operator: %s %s %s", left, operator, right)
+	}
+	return Any
+}
+
+// checkTimeInfixExpression function returns the type of the arithmetic and the comparison of times and durations
+// the second result is false if the operator is not applied to the operands, as evalTimeInfixExpression of the evaluator
+func checkTimeInfixExpression(operator string, left, right Type) (Type, bool) {
+	switch operator {
+	case "<", ">", "<=", ">=":
+		if (left == Time || left == Duration) && left == right {
+			return Bool, true
+		}
+	case "+":
+		switch {
+		case left == Duration && right == Duration:
+			return Duration, true
+		case (left == Time && right == Duration) || (left == Duration && right == Time):
+			return Time, true
+		}
+	case "-":
+		switch {
+		case left == Time && right == Time, left == Duration && right == Duration:
+			return Duration, true
+		case left == Time && right == Duration:
+			return Time, true
+		}
+	case "*":
+		if (left == Duration && right == Int) || (left == Int && right == Duration) {
+			return Duration, true
+		}
+	case "/":
+		switch {
+		case left == Duration && right == Int:
+			return Duration, true
+		case left == Duration && right == Duration:
+			return Int, true
+		}
+	}
+	return nil, false
+}
+
+// isSequence function reports whether the values of the type are ordered lexicographically by the ordering operators
+func isSequence(typ Type) bool {
+	switch typ.(type) {
+	case *Array, *Tuple:
+		return true
+	}
+	return typ == String || typ == Bytes
+}
+
+// checkCallExpression method of checker struct checks the arguments against the parameters of the callee
+func (c *checker) checkCallExpression(ce *ast.CallExpression) Type {
+	if ident, ok := ce.Function.(*ast.Identifier); ok && ident.Value == "quote" {
+		return Any // the argument of quote is not evaluated
+	}
+	callee := c.checkExpression(ce.Function)
+	args := []Type{}
+	spread := false
+	for _, arg := range ce.Arguments {
+		if _, ok := arg.(*ast.SpreadExpression); ok {
+			spread = true
+		}
+		args = append(args, c.checkExpression(arg))
+	}
+
+	switch fn := callee.(type) {
+	case *Function:
+		if fn.Variadic || spread {
+			return fn.Return
+		}
+		name := ce.Function.String()
+		if len(args) != len(fn.Parameters) {
+			c.errorf("wrong number of arguments to `%s`, got=%d, want=%d", name, len(args), len(fn.Parameters))
+			return fn.Return
+		}
+		for i, arg := range args {
+			if !assignable(arg, fn.Parameters[i]) {
+				c.errorf("cannot use %s as %s in argument %d of `%s`", arg, fn.Parameters[i], i+1, name)
+			}
+		}
+		return fn.Return
+	default:
+		if callee != Any {
+			c.errorf("not a function: %s", callee)
+		}
+		return Any
+	}
+}
+
+// checkElements method of checker struct returns the common type of the elements of a list
+func (c *checker) checkElements(elements []ast.Expression) Type {
+	var typ Type
+	for _, element := range elements {
+		var elementType Type
+		if spread, ok := element.(*ast.SpreadExpression); ok {
+			elementType = c.spreadElement(c.checkExpression(spread.Value))
+		} else {
+			elementType = c.checkExpression(element)
+		}
+		if typ == nil {
+			typ = elementType
+		} else {
+			typ = join(typ, elementType)
+		}
+	}
+	if typ == nil {
+		return Any
+	}
+	return typ
+}
+
+// spreadElement method of checker struct returns the type of the elements spliced by a spread
+func (c *checker) spreadElement(typ Type) Type {
+	switch typ := typ.(type) {
+	case *Array:
+		return typ.Element
+	case *Set:
+		return typ.Element
+	case *Tuple:
+		return joinAll(typ.Elements)
+	default:
+		if typ == Range {
+			return Int
+		}
+		return Any
+	}
+}
+
+// checkHashLiteral method of checker struct
+func (c *checker) checkHashLiteral(hl *ast.HashLiteral) Type {
+	var key, value Type
+	add := func(k, v Type) {
+		if key == nil {
+			key, value = k, v
+			return
+		}
+		key, value = join(key, k), join(value, v)
+	}
+	for _, pair := range hl.Pairs {
+		if pair.Spread == nil {
+			add(c.checkExpression(pair.Key), c.checkExpression(pair.Value))
+			continue
+		}
+		switch typ := c.checkExpression(pair.Spread.Value).(type) {
+		case *Hash:
+			add(typ.Key, typ.Value)
+		default:
+			add(Any, Any)
+		}
+	}
+	if key == nil {
+		return &Hash{Key: Any, Value: Any}
+	}
+	return &Hash{Key: key, Value: value}
+}
+
+// checkUnpackStatement method of checker struct checks the number of the names against the tuple
+func (c *checker) checkUnpackStatement(stmt *ast.UnpackStatement) {
+	typ := c.checkExpression(stmt.Value)
+	elements := make([]Type, len(stmt.Names))
+	switch typ := typ.(type) {
+	case *Tuple:
+		if len(typ.Elements) != len(stmt.Names) {
+			c.errorf("cannot unpack %s into %d names", typ, len(stmt.Names))
+			break
+		}
+		copy(elements, typ.Elements)
+	case *Array:
+		for i := range elements {
+			elements[i] = typ.Element
+		}
+	default:
+		if typ != Any {
+			c.errorf("cannot unpack %s", typ)
+		}
+	}
+	for i, name := range stmt.Names {
+		if elements[i] == nil {
+			elements[i] = Any
+		}
+		c.define(name.Value, elements[i])
+	}
+}
+
+// checkIndexExpression method of checker struct
+func (c *checker) checkIndexExpression(ie *ast.IndexExpression) Type {
+	left := c.checkExpression(ie.Left)
+	index := c.checkExpression(ie.Index)
+	if ie.Optional {
+		return Any
+	}
+	switch typ := left.(type) {
+	case *Array:
+		switch index {
+		case Range:
+			return typ
+		case Int, Any:
+			return typ.Element
+		}
+	case *Hash:
+		return typ.Value
+	case *Set:
+		return Bool
+	case *Tuple:
+		if literal, ok := ie.Index.(*ast.IntegerLiteral); ok {
+			if 0 <= literal.Value && literal.Value < int64(len(typ.Elements)) {
+				return typ.Elements[literal.Value]
+			}
+			return Null
+		}
+		switch index {
+		case Range:
+			return Any
+		case Int, Any:
+			return joinAll(typ.Elements)
+		}
+	case *Struct:
+		if name, ok := ie.Index.(*ast.StringLiteral); ok && !typ.hasField(name.Value) {
+			c.errorf("unknown field `%s` of %s", name.Value, typ.Name)
+		}
+		return Any
+	}
+	switch {
+	case left == Any || index == Any:
+		return Any
+	case (left == Range || left == Bytes) && index == Int:
+		return Int
+	case left == String && index == Int:
+		return String
+	case (left == Time || left == Duration) && index == String:
+		return c.checkTimeField(ie, left)
+	case (left == Range || left == String || left == Bytes) && index == Range:
+		return left
+	default:
+		c.errorf("index operator not supported: %s[%s]", left, index)
+		return Any
+	}
+}
+
+// timeFields holds the types of the fields of times and durations, as evalTimeIndexExpression and evalDurationIndexExpression give
+var timeFields = map[Type]map[string]Type{
+	Time: {
+		"year": Int, "month": Int, "day": Int, "hour": Int, "minute": Int, "second": Int, "nanosecond": Int,
+		"weekday": String, "yearday": Int, "zone": String, "offset": Int, "unix": Int,
+	},
+	Duration: {
+		"hours": Int, "minutes": Int, "seconds": Int, "milliseconds": Int, "nanoseconds": Int,
+	},
+}
+
+// checkTimeField method of checker struct returns the type of the field of a time or a duration, such as t.year
+func (c *checker) checkTimeField(ie *ast.IndexExpression, left Type) Type {
+	name, ok := ie.Index.(*ast.StringLiteral)
+	if !ok {
+		return Any
+	}
+	typ, ok := timeFields[left][name.Value]
+	if !ok {
+		c.errorf("unknown field `%s` of %s", name.Value, left)
+		return Any
+	}
+	return typ
+}
+
+// checkUpdateExpression method of checker struct
+func (c *checker) checkUpdateExpression(ue *ast.UpdateExpression) Type {
+	record := c.checkExpression(ue.Record)
+	for _, value := range ue.Values {
+		c.checkExpression(value)
+	}
+	switch typ := record.(type) {
+	case *Struct:
+		for _, field := range ue.Fields {
+			if !typ.hasField(field.Value) {
+				c.errorf("unknown field `%s` of %s", field.Value, typ.Name)
+			}
+		}
+		return typ
+	default:
+		if record != Any {
+			c.errorf("with operator not supported: %s", record)
+		}
+		return Any
+	}
+}
+
+// functionName function
+func functionName(name string) string {
+	if name == "" {
+		return "function"
+	}
+	return "`" + name + "`"
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/BOBO1997/monkey/lexer"
+	"github.com/BOBO1997/monkey/parser"
+)
+
+func TestCheckWellTypedPrograms(t *testing.T) {
+	tests := []string{
+		"let x = 1; let y = x + 2; y * 3",
+		`let s: string = "a" + "b";`,
+		"let add = fn(x: int, y: int) -> int { x + y }; add(1, 2) + 3",
+		"fn fact(n: int) -> int { if (n == 0) { return 1; } n * fact(n - 1) } fact(5)",
+		"let f = fn(x) { x + 1 }; f(true)",
+		"let xs: [int] = [1, 2, 3]; xs[0] + 1",
+		`let h: {string: int} = {"a": 1}; h["a"] + 1`,
+		"let apply = fn(f: fn(int) -> int, x: int) -> int { f(x) }; apply(fn(x) { x * 2 }, 1)",
+		"let f = fn() { g() }; let g = fn() { 1 }; f()",
+		"fn isEven(n) { if (n == 0) { true } else { isOdd(n - 1) } } fn isOdd(n) { if (n == 0) { false } else { isEven(n - 1) } }",
+		"let x = 1; if (true) { let y = x; let x = 2; y + x }",
+		"struct Point { x, y } let p: Point = Point(1, 2); p.x; p with { y: 3 }",
+		"enum State { Pending, Done(result) } let s: State = State.Done(1); is(s, State.Done)",
+		"let r: range = 0..10; r[1] + len(r)",
+		"let xs = [1, 2]; let ys: [int] = [...xs, 3];",
+		"let x = try { throw 1; } catch (e) { e + 1 };",
+		"let x: any = 1; x + true",
+		"puts(1, 2, 3); let x: null = puts();",
+		"fn gen() { yield 1; } array(take(gen(), 1))",
+		"let n: int = null ?? 1;",
+		"let f: fn(int) -> any = fn(x: int) -> int { x };",
+		`let b: bool = "a" < "b"; let c: bool = [1, 2] <= [1, 3];`,
+		"let price: decimal = 12.50d * 2 + 1; let cheap: bool = price < 30; let d: decimal = -price / 3;",
+		"let f = fn(x) { x + 1 }; let d: decimal = f(1.5d); let r: decimal = round(d, 2);",
+		"let s: #{int} = #{1, 2} | set([3]); let b: bool = s[1]; let t: #{int} = s & #{2} - #{};",
+		`let s: #{int} = #{1, 2} - #{"a"}; let t = #{1} & #{"a"}; let n: int = len(s - #{true} - #{});`,
+		`fn divmod(a: int, b: int) -> (int, int) { return a / b, a - a / b * b; } let (q, r) = divmod(7, 2); q + r`,
+		`let t: (int, string) = (1, "a"); let n: int = t[0]; let s: string = t[1]; let b: bool = t < (2, "b");`,
+		"let (a, b) = [1, 2]; a + b",
+		`let c: string = "héllo"[1]; let n: int = bytelen(c); let g: [string] = graphemes(c);`,
+		`let b: bytes = b"\x00" + encode("a", "utf-8"); let n: int = b[0]; let s: string = hex(b[0..1]) + decode(b, "ascii");`,
+		`let rows: [any] = csv_parse("a,b", {"header": false}); let s: string = csv_format(rows, {"delimiter": ";"});`,
+		`let s: string = json_stringify(json_parse("[1]"), {"indent": 2}); let n: int = len(s);`,
+		`let re: regex = r"\d+"; let ok: bool = match(re, "1"); let parts: [string] = split(re, "a1b"); let all: [string] = findall("a", "aa");`,
+		`let t: time = now() + duration("1h") * 2; let d: duration = t - time(0); let n: int = t.year + d / duration("1s"); let b: bool = t < now();`,
+	}
+
+	for _, input := range tests {
+		errors := checkInput(t, input)
+		if len(errors) != 0 {
+			t.Errorf("unexpected type errors for %q: %v", input, errors)
+		}
+	}
+}
+
+func TestCheckTypeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1 + "a"`, "type mismatch: int + string"},
+		{"true + false", "unknown operator: bool + bool"},
+		{"true < false", "unknown operator: bool < bool"},
+		{`[1] < ["a"]`, "type mismatch: [int] < [string]"},
+		{"-true", "unknown operator: -bool"},
+		{"let x = 1; let y = x + true;", "type mismatch: int + bool"},
+		{`let x: int = "a";`, "cannot use string as int in let x"},
+		{"let f = fn(x: int) { x }; f(true)", "cannot use bool as int in argument 1 of `f`"},
+		{"let f = fn(x: int) { x }; f(1, 2)", "wrong number of arguments to `f`, got=2, want=1"},
+		{"len(1, 2)", "wrong number of arguments to `len`, got=2, want=1"},
+		{`fn f() -> int { "a" }`, "cannot use string as int in return of `f`"},
+		{`let f = fn(x) -> int { if (x) { return "a"; } 1 };`, "cannot use string as int in return of `f`"},
+		{"fn(x) -> bool { 1 }", "cannot use int as bool in return of function"},
+		{"let f = fn() { 1 }; let s: string = f();", "cannot use int as string in let s"},
+		{"x + 1", "identifier not found: x"},
+		{"let y = x; let x = 1;", "identifier not found: x"},
+		{"if (true) { let x = 1; } x", "identifier not found: x"},
+		{"let x: Foo = 1;", "unknown type: Foo"},
+		{`let xs: [int] = ["a"];`, "cannot use [string] as [int] in let xs"},
+		{`let h: {string: int} = {"a": "b"};`, "cannot use {string: string} as {string: int} in let h"},
+		{"1(2)", "not a function: int"},
+		{`let x = 1; x["a"]`, "index operator not supported: int[string]"},
+		{`[1, 2]["a"]`, "index operator not supported: [int][string]"},
+		{"struct Point { x, y } Point(1, 2).z", "unknown field `z` of Point"},
+		{"struct Point { x, y } Point(1)", "wrong number of arguments to `Point`, got=1, want=2"},
+		{"struct Point { x, y } Point(1, 2) with { z: 1 }", "unknown field `z` of Point"},
+		{"1 with { x: 1 }", "with operator not supported: int"},
+		{"struct Point { x } struct Pair { x } let p: Point = Pair(1);", "cannot use Pair as Point in let p"},
+		{"let f: fn(int) -> int = fn(x: string) { 1 };", "cannot use fn(string) -> int as fn(int) -> int in let f"},
+		{"let f = fn(g: fn(int) -> int) { g(1) }; f(fn(x: string) { x })", "cannot use fn(string) -> string as fn(int) -> int in argument 1 of `f`"},
+		{"fn f() { 1 + true }", "type mismatch: int + bool"},
+		{"let x: int = 1.5d + 1;", "cannot use decimal as int in let x"},
+		{`1.5d + "a"`, "type mismatch: decimal + string"},
+		{`#{1} | #{"a"}`, "type mismatch: #{int} | #{string}"},
+		{`#{1} - #{"a"} | #{"b"}`, "type mismatch: #{int} | #{string}"},
+		{`let s: #{string} = #{1};`, "cannot use #{int} as #{string} in let s"},
+		{`let t: (int, int) = (1, "a");`, "cannot use (int, string) as (int, int) in let t"},
+		{"let (a, b) = (1, 2, 3);", "cannot unpack (int, int, int) into 2 names"},
+		{"let (a, b) = 1;", "cannot unpack int"},
+		{`let (a, b) = (1, "a"); a + b`, "type mismatch: int + string"},
+		{`fn f() -> (int, bool) { return 1, 2; }`, "cannot use (int, int) as (int, bool) in return of `f`"},
+		{"let x = 1; defer puts(x + true);", "type mismatch: int + bool"},
+		{`b"a" + "a"`, "type mismatch: bytes + string"},
+		{`hex("a")`, "cannot use string as bytes in argument 1 of `hex`"},
+		{"now() + now()", "unknown operator: time + time"},
+		{"now().century", "unknown field `century` of time"},
+	}
+
+	for _, tt := range tests {
+		errors := checkInput(t, tt.input)
+		if len(errors) != 1 {
+			t.Errorf("wrong number of type errors for %q. want=1, got=%d: %v", tt.input, len(errors), errors)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong type error for %q. want=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestCheckInfersFunctionTypes(t *testing.T) {
+	input := `
+let double = fn(x: int) { x * 2 };
+let greet = fn(name) { "hello " + name };
+let pick = fn(b) { if (b) { 1 } else { "one" } };
+let s: string = double(1);
+let n: int = greet("a");
+let any: int = pick(true);
+`
+	expected := []string{
+		"cannot use int as string in let s",
+		"cannot use string as int in let n",
+	}
+
+	errors := checkInput(t, input)
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong type errors. want=%v, got=%v", expected, errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("wrong type error. want=%q, got=%q", msg, errors[i])
+		}
+	}
+}
+
+func checkInput(t *testing.T, input string) []string {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	return Check(program)
+}
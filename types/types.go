@@ -0,0 +1,244 @@
+package types
+
+import (
+	"strings"
+)
+
+// Type interface is implemented by the static types of Monkey values
+type Type interface {
+	String() string
+}
+
+// Basic struct is a type without components
+type Basic struct {
+	Name string
+}
+
+// String method of Basic struct
+func (b *Basic) String() string {
+	return b.Name
+}
+
+// basic types
+var (
+	Int      = &Basic{Name: "int"}
+	Decimal  = &Basic{Name: "decimal"}
+	String   = &Basic{Name: "string"}
+	Bytes    = &Basic{Name: "bytes"}
+	Time     = &Basic{Name: "time"}
+	Duration = &Basic{Name: "duration"}
+	Regex    = &Basic{Name: "regex"}
+	Bool     = &Basic{Name: "bool"}
+	Null     = &Basic{Name: "null"}
+	Range    = &Basic{Name: "range"}
+	Any      = &Basic{Name: "any"} // unknown type, which is compatible with every type
+)
+
+// Array struct is the type of arrays of Element
+type Array struct {
+	Element Type
+}
+
+// String method of Array struct
+func (a *Array) String() string {
+	return "[" + a.Element.String() + "]"
+}
+
+// Tuple struct is the type of tuples of Elements
+type Tuple struct {
+	Elements []Type
+}
+
+// String method of Tuple struct
+func (t *Tuple) String() string {
+	elements := []string{}
+	for _, element := range t.Elements {
+		elements = append(elements, element.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// Set struct is the type of sets of Element
+type Set struct {
+	Element Type
+}
+
+// String method of Set struct
+func (s *Set) String() string {
+	return "#{" + s.Element.String() + "}"
+}
+
+// Hash struct is the type of hashes from Key to Value
+type Hash struct {
+	Key   Type
+	Value Type
+}
+
+// String method of Hash struct
+func (h *Hash) String() string {
+	return "{" + h.Key.String() + ": " + h.Value.String() + "}"
+}
+
+// Function struct is the type of functions
+// the parameters of a variadic function are not checked, as for some builtin functions
+type Function struct {
+	Parameters []Type
+	Return     Type
+	Variadic   bool
+}
+
+// String method of Function struct
+func (f *Function) String() string {
+	if f.Variadic {
+		return "fn(...) -> " + f.Return.String()
+	}
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+	return "fn(" + strings.Join(params, ", ") + ") -> " + f.Return.String()
+}
+
+// Struct struct is the type of the records of a struct declaration
+// two struct types are the same only if they come from the same declaration
+type Struct struct {
+	Name   string
+	Fields []string
+}
+
+// String method of Struct struct
+func (s *Struct) String() string {
+	return s.Name
+}
+
+// hasField method of Struct struct
+func (s *Struct) hasField(name string) bool {
+	for _, field := range s.Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Enum struct is the type of the values of an enum declaration
+type Enum struct {
+	Name string
+}
+
+// String method of Enum struct
+func (e *Enum) String() string {
+	return e.Name
+}
+
+// identical function reports whether the two types are the same
+func identical(a, b Type) bool {
+	switch a := a.(type) {
+	case *Array:
+		b, ok := b.(*Array)
+		return ok && identical(a.Element, b.Element)
+	case *Tuple:
+		b, ok := b.(*Tuple)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !identical(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Set:
+		b, ok := b.(*Set)
+		return ok && identical(a.Element, b.Element)
+	case *Hash:
+		b, ok := b.(*Hash)
+		return ok && identical(a.Key, b.Key) && identical(a.Value, b.Value)
+	case *Function:
+		b, ok := b.(*Function)
+		if !ok || a.Variadic != b.Variadic || len(a.Parameters) != len(b.Parameters) || !identical(a.Return, b.Return) {
+			return false
+		}
+		for i := range a.Parameters {
+			if !identical(a.Parameters[i], b.Parameters[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b // basic types, structs and enums are compared by identity
+	}
+}
+
+// assignable function reports whether a value of the type from can be used where the type to is expected
+// any is assignable to and from every type, also as a component of arrays, tuples, sets, hashes and functions
+func assignable(from, to Type) bool {
+	if from == Any || to == Any {
+		return true
+	}
+	switch to := to.(type) {
+	case *Array:
+		from, ok := from.(*Array)
+		return ok && assignable(from.Element, to.Element)
+	case *Tuple:
+		from, ok := from.(*Tuple)
+		if !ok || len(from.Elements) != len(to.Elements) {
+			return false
+		}
+		for i := range to.Elements {
+			if !assignable(from.Elements[i], to.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Set:
+		from, ok := from.(*Set)
+		return ok && assignable(from.Element, to.Element)
+	case *Hash:
+		from, ok := from.(*Hash)
+		return ok && assignable(from.Key, to.Key) && assignable(from.Value, to.Value)
+	case *Function:
+		from, ok := from.(*Function)
+		if !ok {
+			return false
+		}
+		if from.Variadic || to.Variadic {
+			return assignable(from.Return, to.Return)
+		}
+		if len(from.Parameters) != len(to.Parameters) || !assignable(from.Return, to.Return) {
+			return false
+		}
+		for i := range to.Parameters {
+			if !assignable(to.Parameters[i], from.Parameters[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return identical(from, to)
+	}
+}
+
+// join function returns the type of a value which is either of a or of b
+// there is no union type, so the values of different types are of any
+func join(a, b Type) Type {
+	if identical(a, b) {
+		return a
+	}
+	return Any
+}
+
+// joinAll function returns the type of a value which is any of the types, or null if there is none
+func joinAll(types []Type) Type {
+	if len(types) == 0 {
+		return Null
+	}
+	result := types[0]
+	for _, typ := range types[1:] {
+		result = join(result, typ)
+	}
+	return result
+}